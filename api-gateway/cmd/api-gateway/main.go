@@ -1,70 +1,150 @@
 package main
 
 import (
+	"api-gateway/internal/backend"
 	"api-gateway/internal/config"
+	"api-gateway/internal/health"
+	"api-gateway/internal/logging"
+	"api-gateway/internal/metrics"
+	"api-gateway/internal/mtls"
 	"api-gateway/internal/router"
+	"api-gateway/internal/tracing"
 	"api-gateway/pkg/proto"
+	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
-	log.Println("Starting API Gateway on port 443")
+	cfg := config.LoadConfig(os.Args[1:])
+	logging.Setup("api-gateway", cfg.Log)
+	slog.Info("Starting API Gateway")
 
-	cfg := config.LoadConfig()
+	metrics.Serve(cfg.MetricsAddr)
 
-	log.Printf("Chat Service URL: %s", cfg.ChatServiceURL)
-	chatGrpcConn, err := grpc.Dial(cfg.ChatServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock()) //TODO change to secure connection for prod
+	shutdownTracing, err := tracing.Setup(context.Background(), "api-gateway", cfg.TracingExporter)
 	if err != nil {
-		log.Fatalf("Was not able to connect to Chat Service: %v", err)
-		return
+		log.Fatalf("Failed to set up tracing: %v", err)
 	}
-	log.Println("Connected to Chat Service")
-	defer chatGrpcConn.Close()
+	defer shutdownTracing(context.Background())
 
-	log.Printf("User Service URL: %s", cfg.UserServiceURL)
-	UsergrpcConn, err := grpc.Dial(cfg.UserServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock()) //TODO change to secure connection for prod
+	// Model calls get the longer generation deadline; everything else uses
+	// RPCTimeout.
+	chatOpts := backend.Options{
+		RPCTimeout: cfg.RPCTimeout,
+		MethodTimeouts: map[string]time.Duration{
+			proto.ChatService_CreateMessage_FullMethodName: cfg.GenerationRPCTimeout,
+		},
+	}
+	lessonOpts := backend.Options{
+		RPCTimeout: cfg.RPCTimeout,
+		MethodTimeouts: map[string]time.Duration{
+			proto.LessonService_GenerateQuickResponse_FullMethodName: cfg.GenerationRPCTimeout,
+			proto.LessonService_GenerateTopicPlan_FullMethodName:     cfg.GenerationRPCTimeout,
+			proto.LessonService_GenerateLessons_FullMethodName:       cfg.GenerationRPCTimeout,
+			proto.LessonService_GenerateTests_FullMethodName:         cfg.GenerationRPCTimeout,
+			proto.LessonService_GradeTest_FullMethodName:             cfg.GenerationRPCTimeout,
+		},
+	}
+	userOpts := backend.Options{RPCTimeout: cfg.RPCTimeout}
+	userTransport := http.DefaultTransport
+
+	if cfg.MTLS.Enabled() {
+		store, err := mtls.NewStore(cfg.MTLS)
+		if err != nil {
+			log.Fatalf("Failed to load mTLS material: %v", err)
+		}
+		chatOpts.TLS = store.ClientConfig(cfg.ChatServiceTLSName)
+		lessonOpts.TLS = store.ClientConfig(cfg.LessonServiceTLSName)
+		userOpts.TLS = store.ClientConfig(cfg.UserServiceTLSName)
+		userTransport = &http.Transport{TLSClientConfig: store.ClientConfig(cfg.UserServiceTLSName)}
+		slog.Info("mTLS enabled for backend connections")
+	} else {
+		slog.Info("mTLS disabled, backend connections are plaintext")
+	}
+
+	userHTTPClient := &http.Client{Transport: otelhttp.NewTransport(userTransport)}
+
+	// Dialing does not wait for the services; the gateway starts degraded
+	// and calls to a service that is down fail with Unavailable until it
+	// comes back.
+	chatBackend, err := backend.Dial("chat", cfg.ChatServiceURL, chatOpts)
 	if err != nil {
-		log.Fatalf("Was not able to connect User Service: %v", err)
-		return
+		log.Fatalf("Was not able to set up Chat Service connection: %v", err)
 	}
-	log.Println("Connected to User Service")
-	defer UsergrpcConn.Close()
+	defer chatBackend.Close()
 
-	log.Printf("Lesson Service URL: %s", cfg.LessonServiceURL)
-	LessongrpcConn, err := grpc.Dial(cfg.LessonServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock()) //TODO change to secure connection for prod
+	userBackend, err := backend.Dial("user", cfg.UserServiceURL, userOpts)
 	if err != nil {
-		log.Fatalf("Was not able to connect Lesson Service: %v", err)
-		return
+		log.Fatalf("Was not able to set up User Service connection: %v", err)
 	}
-	log.Println("Connected to Lesson Service")
-	defer LessongrpcConn.Close()
+	defer userBackend.Close()
 
-	chatClient := proto.NewChatServiceClient(chatGrpcConn)
-	userClient := proto.NewUserServiceClient(UsergrpcConn)
-	lessonClient := proto.NewLessonServiceClient(LessongrpcConn)
+	lessonBackend, err := backend.Dial("lesson", cfg.LessonServiceURL, lessonOpts)
+	if err != nil {
+		log.Fatalf("Was not able to set up Lesson Service connection: %v", err)
+	}
+	defer lessonBackend.Close()
 
-	router := router.LoadRouter(cfg, chatClient, userClient, lessonClient)
-	log.Println("HTTP router loaded")
+	chatClient := proto.NewChatServiceClient(chatBackend.Conn)
+	userClient := proto.NewUserServiceClient(userBackend.Conn)
+	lessonClient := proto.NewLessonServiceClient(lessonBackend.Conn)
 
-	keyPath := "/run/secrets/ssl_key"
-	certPath := "/run/secrets/ssl_cert"
+	prober := health.NewProber(chatBackend, userBackend, lessonBackend)
+	router, wsManager := router.LoadRouter(cfg, chatClient, userClient, lessonClient, userHTTPClient, prober)
+	slog.Info("HTTP router loaded")
 
-	if _, err := os.Stat(certPath); os.IsNotExist(err) {
-		log.Fatalf("SSL certificate not found at %s", certPath)
+	if _, err := os.Stat(cfg.HTTPSCertFile); os.IsNotExist(err) {
+		log.Fatalf("SSL certificate not found at %s", cfg.HTTPSCertFile)
 	}
-	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
-		log.Fatalf("SSL key not found at %s", keyPath)
+	if _, err := os.Stat(cfg.HTTPSKeyFile); os.IsNotExist(err) {
+		log.Fatalf("SSL key not found at %s", cfg.HTTPSKeyFile)
 	}
 
-	log.Println("Starting HTTPS server")
-	err = http.ListenAndServeTLS(":443", certPath, keyPath, router)
-	if err != nil {
-		log.Fatalf("Failed to start HTTPS server: %v", err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: cfg.ListenAddr, Handler: router}
+	go func() {
+		slog.Info("Starting HTTPS server", "addr", cfg.ListenAddr)
+		if err := server.ListenAndServeTLS(cfg.HTTPSCertFile, cfg.HTTPSKeyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start HTTPS server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down, draining connections", "grace_period", cfg.ShutdownGracePeriod)
+	prober.SetDraining()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+	defer cancel()
 
+	// Hijacked WebSocket connections are not tracked by http.Server, so the
+	// manager drains them while the server waits for REST and SSE requests.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := wsManager.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Grace period ended with socket actions still running", "err", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Grace period ended with HTTP requests still running", "err", err)
+		}
+	}()
+	wg.Wait()
+	slog.Info("API Gateway stopped")
 }
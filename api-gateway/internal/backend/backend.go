@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"api-gateway/internal/metrics"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // client-side health checking
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+)
+
+// serviceConfig balances calls across every address the target resolves to,
+// skips replicas whose grpc.health.v1 status is not SERVING, and retries
+// reads that never reached a healthy replica. Only reads are listed: a
+// generation or create call may have run before the connection dropped, and
+// retrying it would bill the model twice or store a duplicate.
+const serviceConfig = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"healthCheckConfig": {"serviceName": ""},
+	"methodConfig": [{
+		"name": [
+			{"service": "proto.ChatService", "method": "GetRecentMessages"},
+			{"service": "proto.ChatService", "method": "GetMessageByID"},
+			{"service": "proto.ChatService", "method": "GetChatSummariesUID"},
+			{"service": "proto.ChatService", "method": "SearchMessages"},
+			{"service": "proto.LessonService", "method": "GetAllTopicPlansByUID"},
+			{"service": "proto.LessonService", "method": "GetTopicPlanByID"},
+			{"service": "proto.LessonService", "method": "GetLessonByID"},
+			{"service": "proto.LessonService", "method": "GetAllLessonPlansByTopicID"},
+			{"service": "proto.LessonService", "method": "GetAllTestsByLessonID"},
+			{"service": "proto.LessonService", "method": "GetAllQuestionsByTestID"},
+			{"service": "proto.UserService", "method": "GetUserInfo"},
+			{"service": "proto.UserService", "method": "ListUsers"},
+			{"service": "proto.UserService", "method": "SearchForUser"}
+		],
+		"retryPolicy": {
+			"maxAttempts": 3,
+			"initialBackoff": "0.2s",
+			"maxBackoff": "2s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// Options configures a backend connection. RPCTimeout bounds every unary
+// call that does not already have a shorter deadline; MethodTimeouts
+// overrides it for slow methods such as model calls.
+// TLS, when set, secures the connection; otherwise it is plaintext.
+type Options struct {
+	RPCTimeout     time.Duration
+	MethodTimeouts map[string]time.Duration
+	TLS            *tls.Config
+}
+
+// Backend is a lazily connected client connection to one service. Dialing
+// never blocks, so the gateway starts with any backend down and calls to it
+// fail fast with Unavailable until it comes back.
+type Backend struct {
+	Name string
+	Conn *grpc.ClientConn
+}
+
+// Dial connects to target, which is either a single gRPC target such as
+// "chat-service:8080" or "dns:///chat-service:8080", or a comma separated
+// list of replica addresses. Bare host:port targets are resolved through DNS
+// so every replica behind the name is used.
+func Dial(name, target string, opts Options) (*Backend, error) {
+	creds := insecure.NewCredentials()
+	if opts.TLS != nil {
+		creds = credentials.NewTLS(opts.TLS)
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: 5 * time.Second,
+		}),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                30 * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor(), deadlineInterceptor(opts), unavailableUnaryInterceptor(name)),
+		grpc.WithChainStreamInterceptor(metrics.StreamClientInterceptor(), unavailableStreamInterceptor(name)),
+	}
+
+	if strings.Contains(target, ",") {
+		r := manual.NewBuilderWithScheme(name)
+		var addrs []resolver.Address
+		for _, addr := range strings.Split(target, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, resolver.Address{Addr: addr})
+			}
+		}
+		r.InitialState(resolver.State{Addresses: addrs})
+		dialOpts = append(dialOpts, grpc.WithResolvers(r))
+		target = r.Scheme() + ":///" + name
+	} else if !strings.Contains(target, ":///") {
+		target = "dns:///" + target
+	}
+
+	conn, err := grpc.Dial(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s service at %s: %w", name, target, err)
+	}
+	slog.Info("Backend target resolved", "backend", name, "target", target)
+	return &Backend{Name: name, Conn: conn}, nil
+}
+
+// Ready reports whether the backend has a usable connection right now. It
+// also nudges an idle connection to start connecting.
+func (b *Backend) Ready() bool {
+	state := b.Conn.GetState()
+	if state == connectivity.Idle {
+		b.Conn.Connect()
+	}
+	return state == connectivity.Ready
+}
+
+func (b *Backend) Close() error {
+	return b.Conn.Close()
+}
+
+func deadlineInterceptor(opts Options) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		timeout := opts.RPCTimeout
+		if t, ok := opts.MethodTimeouts[method]; ok {
+			timeout = t
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
+
+// unavailableUnaryInterceptor names the service in Unavailable errors so a
+// client can tell which part of the platform is degraded.
+func unavailableUnaryInterceptor(name string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return unavailable(name, invoker(ctx, method, req, reply, cc, callOpts...))
+	}
+}
+
+func unavailableStreamInterceptor(name string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		return stream, unavailable(name, err)
+	}
+}
+
+func unavailable(name string, err error) error {
+	if status.Code(err) != codes.Unavailable {
+		return err
+	}
+	return status.Errorf(codes.Unavailable, "%s service unavailable: %s", name, status.Convert(err).Message())
+}
@@ -1,8 +1,15 @@
 package config
 
 import (
+	"api-gateway/internal/logging"
+	"api-gateway/internal/mtls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"time"
 )
 
 type Config struct {
@@ -12,29 +19,169 @@ type Config struct {
 	UserServiceURL     string
 	ChatServiceURL     string
 	LessonServiceURL   string
+
+	// ListenAddr is where the HTTPS server listens, with the certificate
+	// and key in HTTPSCertFile and HTTPSKeyFile.
+	ListenAddr    string
+	HTTPSCertFile string
+	HTTPSKeyFile  string
+
+	// MTLS secures the links to the services. Each service's certificate
+	// must carry its TLS name as a DNS SAN.
+	MTLS                 mtls.Config
+	UserServiceTLSName   string
+	ChatServiceTLSName   string
+	LessonServiceTLSName string
+
+	// RPCTimeout bounds unary backend calls; GenerationRPCTimeout applies
+	// to the ones that wait on a model.
+	RPCTimeout           time.Duration
+	GenerationRPCTimeout time.Duration
+
+	// ShutdownGracePeriod is how long open sockets, streams and requests
+	// get to finish after SIGTERM before they are cut off.
+	ShutdownGracePeriod time.Duration
+
+	// Rate limits per action class. Generation covers every action that
+	// ends in a model call; GenerationPerDay caps a user's total of those.
+	ReadPerMinute       int
+	ReadBurst           int
+	GenerationPerMinute int
+	GenerationBurst     int
+	GenerationPerDay    int
+
+	Log logging.Config
+
+	MetricsAddr string
+
+	// TracingExporter is "otlp", "stdout" or "none".
+	TracingExporter string
+
+	// PrintConfig is set by --print-config.
+	PrintConfig bool
+
+	layers *layers
+}
+
+var settings = []setting{
+	{Key: "ACCESS_SECRET", Secret: true, Usage: "key that verifies access tokens"},
+	{Key: "REFRESH_SECRET", Secret: true, Usage: "key that verifies refresh tokens"},
+	{Key: "USER_HTTP_SERVICE_URL", Default: "http://user-subscription-service:8080", Usage: "base URL of the user service's HTTP API"},
+	{Key: "USER_SERVICE_ADDR", Default: "user-subscription-service:8081", Usage: "user service gRPC target or comma separated replicas"},
+	{Key: "CHAT_SERVICE_ADDR", Default: "chat-service:8080", Usage: "chat service gRPC target or comma separated replicas"},
+	{Key: "LESSON_SERVICE_ADDR", Default: "lesson-service:8085", Usage: "lesson service gRPC target or comma separated replicas"},
+	{Key: "LISTEN_ADDR", Default: ":443", Usage: "HTTPS listen address"},
+	{Key: "HTTPS_CERT_FILE", Default: "/run/secrets/ssl_cert", Usage: "HTTPS certificate"},
+	{Key: "HTTPS_KEY_FILE", Default: "/run/secrets/ssl_key", Usage: "HTTPS private key"},
+	{Key: "TLS_CA_FILE", Usage: "CA bundle that signs service certificates"},
+	{Key: "TLS_CERT_FILE", Usage: "client certificate, enables mTLS to the services"},
+	{Key: "TLS_KEY_FILE", Usage: "client private key"},
+	{Key: "TLS_ALLOWED_PEERS", Usage: "comma separated SANs accepted from services"},
+	{Key: "USER_SERVICE_TLS_NAME", Default: "user-subscription-service", Usage: "SAN expected on the user service certificate"},
+	{Key: "CHAT_SERVICE_TLS_NAME", Default: "chat-service", Usage: "SAN expected on the chat service certificate"},
+	{Key: "LESSON_SERVICE_TLS_NAME", Default: "lesson-service", Usage: "SAN expected on the lesson service certificate"},
+	{Key: "RPC_TIMEOUT", Default: "15s", Usage: "deadline for unary backend calls"},
+	{Key: "GENERATION_RPC_TIMEOUT", Default: "3m", Usage: "deadline for backend calls that wait on a model"},
+	{Key: "SHUTDOWN_GRACE_PERIOD", Default: "30s", Usage: "time sockets and requests get to finish on shutdown"},
+	{Key: "RATE_LIMIT_READ_PER_MINUTE", Default: "120", Usage: "read actions per user and action per minute"},
+	{Key: "RATE_LIMIT_READ_BURST", Default: "30", Usage: "burst of read actions"},
+	{Key: "RATE_LIMIT_GENERATION_PER_MINUTE", Default: "6", Usage: "generation actions per user and action per minute"},
+	{Key: "RATE_LIMIT_GENERATION_BURST", Default: "3", Usage: "burst of generation actions"},
+	{Key: "RATE_LIMIT_GENERATION_PER_DAY", Default: "200", Usage: "generation actions per user per day"},
+	{Key: "LOG_LEVEL", Default: "info", Usage: "debug, info, warn or error"},
+	{Key: "LOG_FORMAT", Default: "json", Usage: "json or text"},
+	{Key: "LOG_REDACT_CONTENT", Default: "true", Usage: "redact user and model content in logs"},
+	{Key: "METRICS_ADDR", Default: ":9090", Usage: "Prometheus metrics listen address"},
+	{Key: "TRACING_EXPORTER", Default: "none", Usage: "otlp, stdout or none"},
 }
 
-func readSecretFile(secretName string) string {
-	secretPath := "/run/secrets/" + secretName
-	content, err := os.ReadFile(secretPath)
+// Load resolves the configuration from args and the process environment.
+// The returned error lists every invalid setting; the Config is still
+// returned with it so --print-config can show what was resolved.
+func Load(args []string) (*Config, error) {
+	l, err := resolve("api-gateway", settings, args, os.LookupEnv)
 	if err != nil {
-		log.Fatalf("Failed to read secret file %s, error: %v", secretPath, err)
+		return nil, err
 	}
-	return string(content)
+
+	p := &parser{l: l}
+	cfg := &Config{
+		AccessSecret:  p.Required("ACCESS_SECRET"),
+		RefreshSecret: p.Required("REFRESH_SECRET"),
+
+		// Service addresses accept a single host:port, any gRPC target such
+		// as dns:///chat-service:8080, or a comma separated list of replicas.
+		UserHTTPServiceURL: p.Required("USER_HTTP_SERVICE_URL"),
+		UserServiceURL:     p.Required("USER_SERVICE_ADDR"),
+		ChatServiceURL:     p.Required("CHAT_SERVICE_ADDR"),
+		LessonServiceURL:   p.Required("LESSON_SERVICE_ADDR"),
+
+		ListenAddr:    p.Addr("LISTEN_ADDR"),
+		HTTPSCertFile: p.Required("HTTPS_CERT_FILE"),
+		HTTPSKeyFile:  p.Required("HTTPS_KEY_FILE"),
+
+		MTLS: mtls.Config{
+			CAFile:       p.String("TLS_CA_FILE"),
+			CertFile:     p.String("TLS_CERT_FILE"),
+			KeyFile:      p.String("TLS_KEY_FILE"),
+			AllowedPeers: p.List("TLS_ALLOWED_PEERS"),
+		},
+		UserServiceTLSName:   p.String("USER_SERVICE_TLS_NAME"),
+		ChatServiceTLSName:   p.String("CHAT_SERVICE_TLS_NAME"),
+		LessonServiceTLSName: p.String("LESSON_SERVICE_TLS_NAME"),
+
+		RPCTimeout:           p.Duration("RPC_TIMEOUT"),
+		GenerationRPCTimeout: p.Duration("GENERATION_RPC_TIMEOUT"),
+
+		ShutdownGracePeriod: p.Duration("SHUTDOWN_GRACE_PERIOD"),
+
+		ReadPerMinute:       p.Int("RATE_LIMIT_READ_PER_MINUTE"),
+		ReadBurst:           p.Int("RATE_LIMIT_READ_BURST"),
+		GenerationPerMinute: p.Int("RATE_LIMIT_GENERATION_PER_MINUTE"),
+		GenerationBurst:     p.Int("RATE_LIMIT_GENERATION_BURST"),
+		GenerationPerDay:    p.Int("RATE_LIMIT_GENERATION_PER_DAY"),
+
+		Log: logging.Config{
+			Level:         p.OneOf("LOG_LEVEL", "debug", "info", "warn", "error"),
+			Format:        p.OneOf("LOG_FORMAT", "json", "text"),
+			RedactContent: p.Bool("LOG_REDACT_CONTENT"),
+		},
+
+		MetricsAddr:     p.Addr("METRICS_ADDR"),
+		TracingExporter: p.OneOf("TRACING_EXPORTER", "otlp", "stdout", "none"),
+
+		PrintConfig: l.printConfig,
+		layers:      l,
+	}
+	p.Check(cfg.MTLS.Validate())
+
+	return cfg, p.Err()
+}
+
+// Print writes the resolved settings and where each came from, with secrets
+// redacted.
+func (c *Config) Print(w io.Writer) {
+	c.layers.Print(w)
 }
 
-func LoadConfig() *Config {
-	userHTTPServiceURL := "http://user-subscription-service:8080"
-	userServiceURL := "user-subscription-service:8081"
-	chatServiceURL := "chat-service:8080"
-	lessonServiceURL := "lesson-service:8085"
-
-	return &Config{
-		AccessSecret:       readSecretFile("ACCESS_SECRET"),
-		RefreshSecret:      readSecretFile("REFRESH_SECRET"),
-		UserHTTPServiceURL: userHTTPServiceURL,
-		UserServiceURL:     userServiceURL,
-		ChatServiceURL:     chatServiceURL,
-		LessonServiceURL:   lessonServiceURL,
+// LoadConfig loads the configuration from args, usually os.Args[1:], and the
+// environment and exits on invalid settings. With --print-config it prints
+// the resolved configuration and exits.
+func LoadConfig(args []string) *Config {
+	cfg, err := Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if cfg != nil && cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\ninvalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
+	return cfg
 }
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings are resolved from these layers, each overriding the ones before
+// it: built-in defaults, an optional YAML or TOML file named by --config or
+// CONFIG_FILE, files in the secrets directory (SECRETS_DIR, /run/secrets by
+// default), environment variables and command-line flags.
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+	sourceSecret  = "secret file"
+	sourceEnv     = "environment"
+	sourceFlag    = "flag"
+)
+
+const defaultSecretsDir = "/run/secrets"
+
+// setting is one configuration value. Key is its environment variable, the
+// name of its file in the secrets directory and its key in the config file.
+// Secret settings are redacted by --print-config and cannot be passed as
+// flags, where they would show up in the process list.
+type setting struct {
+	Key     string
+	Default string
+	Secret  bool
+	Usage   string
+}
+
+type value struct {
+	raw    string
+	source string
+}
+
+// layers holds the resolved value and origin of every setting.
+type layers struct {
+	settings    []setting
+	values      map[string]value
+	secretsDir  string
+	printConfig bool
+}
+
+// flagName turns ACCESS_SECRET into access-secret.
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// normalizeKey maps config file keys such as "rpc-timeout" or nested
+// "rate_limit.read.burst" onto setting keys.
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+func resolve(name string, settings []setting, args []string, lookupEnv func(string) (string, bool)) (*layers, error) {
+	env := func(key string) string {
+		v, _ := lookupEnv(key)
+		return v
+	}
+
+	l := &layers{
+		settings:   settings,
+		values:     make(map[string]value, len(settings)),
+		secretsDir: env("SECRETS_DIR"),
+	}
+	if l.secretsDir == "" {
+		l.secretsDir = defaultSecretsDir
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", env("CONFIG_FILE"), "YAML or TOML config file")
+	fs.BoolVar(&l.printConfig, "print-config", false, "print the resolved configuration with secrets redacted and exit")
+	flagValues := make(map[string]*string)
+	for _, s := range settings {
+		if !s.Secret {
+			flagValues[s.Key] = fs.String(flagName(s.Key), "", s.Usage+" (env "+s.Key+")")
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	for _, s := range settings {
+		l.values[s.Key] = value{raw: s.Default, source: sourceDefault}
+	}
+
+	if *configFile != "" {
+		fileValues, err := readConfigFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+		var unknown []string
+		for key, raw := range fileValues {
+			if _, ok := l.values[key]; !ok {
+				unknown = append(unknown, key)
+				continue
+			}
+			l.values[key] = value{raw: raw, source: sourceFile + " " + *configFile}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("%s: unknown settings %s", *configFile, strings.Join(unknown, ", "))
+		}
+	}
+
+	for _, s := range settings {
+		path := filepath.Join(l.secretsDir, s.Key)
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read secret file %s: %w", path, err)
+		}
+		// Secrets written with echo or an editor end in a newline that is
+		// not part of the value.
+		l.values[s.Key] = value{raw: strings.TrimRight(string(content), "\r\n"), source: sourceSecret + " " + path}
+	}
+
+	// A variable that is set but empty still overrides the layers below,
+	// so FOO= clears a value the config file or a secret file gave FOO.
+	for _, s := range settings {
+		if raw, ok := lookupEnv(s.Key); ok {
+			l.values[s.Key] = value{raw: raw, source: sourceEnv}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		key := normalizeKey(f.Name)
+		if _, ok := flagValues[key]; ok {
+			l.values[key] = value{raw: f.Value.String(), source: sourceFlag}
+		}
+	})
+
+	return l, nil
+}
+
+// readConfigFile flattens a YAML or TOML file into setting keys. Nested
+// tables join their keys with underscores and lists become comma separated
+// values.
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	doc := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	case ".toml":
+		err = toml.Unmarshal(content, &doc)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", doc, values)
+	return values, nil
+}
+
+func flatten(prefix string, node map[string]interface{}, out map[string]string) {
+	for key, v := range node {
+		key = normalizeKey(key)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flatten(key, v, out)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// Print writes every setting with its origin. Secrets only show whether they
+// are set.
+func (l *layers) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, s := range l.settings {
+		v := l.values[s.Key]
+		raw := v.raw
+		if s.Secret && raw != "" {
+			raw = "[REDACTED]"
+		}
+		if raw == "" {
+			raw = "(unset)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, raw, v.source)
+	}
+	tw.Flush()
+}
+
+// parser converts resolved settings to typed values and collects every
+// problem so a misconfigured service reports them all at once.
+type parser struct {
+	l    *layers
+	errs []error
+}
+
+func (p *parser) invalid(key, format string, args ...interface{}) {
+	v := p.l.values[key]
+	shown := fmt.Sprintf("%q", v.raw)
+	for _, s := range p.l.settings {
+		if s.Key == key && s.Secret {
+			shown = "value"
+		}
+	}
+	p.errs = append(p.errs, fmt.Errorf("%s: invalid %s from %s: %s", key, shown, v.source, fmt.Sprintf(format, args...)))
+}
+
+// String returns the raw value of key; it also serves as a lookup function
+// for packages that read their own settings.
+func (p *parser) String(key string) string {
+	return p.l.values[key].raw
+}
+
+func (p *parser) Required(key string) string {
+	raw := p.String(key)
+	if raw == "" {
+		p.errs = append(p.errs, fmt.Errorf("%s: required; set it in the environment, the config file or %s", key, filepath.Join(p.l.secretsDir, key)))
+	}
+	return raw
+}
+
+func (p *parser) Int(key string) int {
+	n, err := strconv.Atoi(p.String(key))
+	if err != nil {
+		p.invalid(key, "not an integer")
+	}
+	return n
+}
+
+func (p *parser) Bool(key string) bool {
+	b, err := strconv.ParseBool(p.String(key))
+	if err != nil {
+		p.invalid(key, "not true or false")
+	}
+	return b
+}
+
+// List splits a comma separated value, dropping empty items.
+func (p *parser) List(key string) []string {
+	var items []string
+	for _, item := range strings.Split(p.String(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (p *parser) Duration(key string) time.Duration {
+	d, err := time.ParseDuration(p.String(key))
+	if err != nil {
+		p.invalid(key, "not a duration such as 30s or 2m")
+	} else if d < 0 {
+		p.invalid(key, "must not be negative")
+	}
+	return d
+}
+
+func (p *parser) Addr(key string) string {
+	raw := p.String(key)
+	if _, port, err := net.SplitHostPort(raw); err != nil {
+		p.invalid(key, "not a host:port address")
+	} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		p.invalid(key, "port is not a number")
+	}
+	return raw
+}
+
+// OneOf checks the value, compared case-insensitively, against allowed.
+func (p *parser) OneOf(key string, allowed ...string) string {
+	raw := strings.ToLower(p.String(key))
+	for _, a := range allowed {
+		if raw == a {
+			return raw
+		}
+	}
+	p.invalid(key, "must be one of %s", strings.Join(allowed, ", "))
+	return raw
+}
+
+// Check records a problem that spans several settings.
+func (p *parser) Check(err error) {
+	if err != nil {
+		p.errs = append(p.errs, err)
+	}
+}
+
+func (p *parser) Err() error {
+	return errors.Join(p.errs...)
+}
@@ -6,74 +6,157 @@ import (
 	"api-gateway/pkg/proto"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// Upper bound on a single AI answer stream.
+const streamTimeout = 5 * time.Minute
+
 type ChatHandler struct {
 	Config     *config.Config
 	ChatClient proto.ChatServiceClient
+	Notifier   middleware.UserNotifier
+
+	streamsMu sync.Mutex
+	streams   map[string]*chatStream
 }
 
-func NewChatHandler(cfg *config.Config, chatClient proto.ChatServiceClient) *ChatHandler {
+func NewChatHandler(cfg *config.Config, chatClient proto.ChatServiceClient, notifier middleware.UserNotifier) *ChatHandler {
 	return &ChatHandler{
 		Config:     cfg,
 		ChatClient: chatClient,
+		Notifier:   notifier,
+		streams:    make(map[string]*chatStream),
 	}
 }
 
-func (h *ChatHandler) ProcessMessage(conn *websocket.Conn, msg middleware.WSMessage) {
-	log.Printf("Raw data: %s", string(msg.Data))
+func (h *ChatHandler) ProcessMessage(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
+	slog.DebugContext(ctx, "Chat action payload", "action", msg.Action, "payload", string(msg.Data))
 
 	switch msg.Action {
 	case "get_chat_summaries":
 		var getChatSummaries proto.GetChatSummariesUIDRequest
 		if err := json.Unmarshal(msg.Data, &getChatSummaries); err != nil {
-			log.Printf("Failed to unmarshal data from getchatsums: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal data from getchatsums", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		go h.GetChatSummaries(conn, msg.JWT, getChatSummaries.Id)
+		h.GetChatSummaries(ctx, conn, msg.RequestID, msg.JWT, getChatSummaries.Id, getChatSummaries.IncludeArchived)
 	case "get_recent_messages":
 		var getRecentMessages proto.GetRecentMessagesRequest
 		if err := json.Unmarshal(msg.Data, &getRecentMessages); err != nil {
-			log.Printf("Failed to unmarshal GetRecentMessagesRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal GetRecentMessagesRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		go h.GetRecentMessages(conn, getRecentMessages.ChatId, getRecentMessages.LastMessageId, getRecentMessages.Limit, msg.JWT)
+		h.GetRecentMessages(ctx, conn, msg.RequestID, getRecentMessages.ChatId, getRecentMessages.LastMessageId, getRecentMessages.Limit, msg.JWT)
 	case "start_message_stream":
 		var createMsgReq proto.CreateMessageRequest
 		if err := json.Unmarshal(msg.Data, &createMsgReq); err != nil {
-			log.Printf("Failed to unmarshal CreateMessageRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal CreateMessageRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
 			return
 		}
-		go h.StreamMessages(conn, createMsgReq.ChatId, createMsgReq.Body, createMsgReq.Sender, msg.JWT)
+		h.StreamMessages(ctx, conn, msg.RequestID, createMsgReq.ChatId, createMsgReq.Body, createMsgReq.Sender, msg.JWT)
 	case "delete_chat_by_chat_id":
 		var deleteChatByIDReq proto.DeleteChatByIDRequest
 		if err := json.Unmarshal(msg.Data, &deleteChatByIDReq); err != nil {
-			log.Printf("Failed to unmarshal DeleteChatByIDRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal DeleteChatByIDRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
 			return
 		}
-		go h.DeleteChatByID(conn, msg.JWT, deleteChatByIDReq.UserId, deleteChatByIDReq.ChatId)
+		h.DeleteChatByID(ctx, conn, msg.RequestID, msg.JWT, deleteChatByIDReq.UserId, deleteChatByIDReq.ChatId)
 	case "delete_all_chats_by_user_id":
 		var deleteAllChatsByUIDReq proto.DeleteAllChatsByUIDRequest
 		if err := json.Unmarshal(msg.Data, &deleteAllChatsByUIDReq); err != nil {
-			log.Printf("Failed to unmarshal DeleteAllChatByIIDRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal DeleteAllChatByIIDRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
+		}
+		h.DeleteAllChatsByUID(ctx, conn, msg.RequestID, msg.JWT, deleteAllChatsByUIDReq.UserId)
+	case "rename_chat":
+		var renameChatReq proto.RenameChatRequest
+		if err := json.Unmarshal(msg.Data, &renameChatReq); err != nil {
+			slog.WarnContext(ctx, "Failed to unmarshal RenameChatRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
+		}
+		h.RenameChat(ctx, conn, msg.RequestID, msg.JWT, &renameChatReq)
+	case "pin_chat":
+		var pinChatReq proto.PinChatRequest
+		if err := json.Unmarshal(msg.Data, &pinChatReq); err != nil {
+			slog.WarnContext(ctx, "Failed to unmarshal PinChatRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
+		}
+		h.PinChat(ctx, conn, msg.RequestID, msg.JWT, &pinChatReq)
+	case "archive_chat":
+		var archiveChatReq proto.ArchiveChatRequest
+		if err := json.Unmarshal(msg.Data, &archiveChatReq); err != nil {
+			slog.WarnContext(ctx, "Failed to unmarshal ArchiveChatRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
 			return
 		}
-		go h.DeleteAllChatsByUID(conn, msg.JWT, deleteAllChatsByUIDReq.UserId)
+		h.ArchiveChat(ctx, conn, msg.RequestID, msg.JWT, &archiveChatReq)
+	case "search_messages":
+		var searchReq proto.SearchMessagesRequest
+		if err := json.Unmarshal(msg.Data, &searchReq); err != nil {
+			slog.WarnContext(ctx, "Failed to unmarshal SearchMessagesRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
+		}
+		h.SearchMessages(ctx, conn, msg.RequestID, msg.JWT, &searchReq)
+	case "cancel_message_stream":
+		var cancelReq struct {
+			StreamID  string `json:"stream_id"`
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal(msg.Data, &cancelReq); err != nil {
+			slog.WarnContext(ctx, "Failed to unmarshal cancel_message_stream", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
+		}
+		h.CancelMessageStream(conn, msg.RequestID, cancelReq.StreamID, cancelReq.RequestID)
+	case "resume_message_stream":
+		var resumeReq struct {
+			StreamID  string `json:"stream_id"`
+			MessageID uint32 `json:"message_id"`
+			LastSeq   int    `json:"last_seq"`
+		}
+		if err := json.Unmarshal(msg.Data, &resumeReq); err != nil {
+			slog.WarnContext(ctx, "Failed to unmarshal resume_message_stream", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
+		}
+		h.ResumeMessageStream(ctx, conn, msg.RequestID, msg.JWT, resumeReq.StreamID, resumeReq.MessageID, resumeReq.LastSeq)
+	default:
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, status.Errorf(codes.Unimplemented, "unknown chat action: %s", msg.Action))
 	}
 }
 
-func (h *ChatHandler) StreamMessages(conn *websocket.Conn, chatID uint32, body string, sender string, jwt string) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *ChatHandler) StreamMessages(ctx context.Context, conn middleware.WSConn, requestID string, chatID uint32, body string, sender string, jwt string) {
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
 	defer cancel()
 
+	cs := newChatStream(ctx, cancel, conn, requestID)
+	h.addStream(cs)
+	go cs.watch(conn)
+
+	middleware.SendWebSocketMessage(conn, "message_stream_started", requestID, StreamEvent{StreamID: cs.id})
+
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	stream, err := h.ChatClient.StreamMessages(ctxWithMetadata)
 	if err != nil {
-		log.Printf("Could not start Stream: %v", err)
+		slog.ErrorContext(ctx, "Could not start Stream", "err", err)
+		cs.finish(middleware.ErrorAction, err)
 		return
 	}
 
@@ -84,84 +167,319 @@ func (h *ChatHandler) StreamMessages(conn *websocket.Conn, chatID uint32, body s
 	}
 
 	if err := stream.Send(req); err != nil {
-		log.Printf("Failed to send a message: %v", err)
+		slog.ErrorContext(ctx, "Failed to send a message", "err", err)
+		cs.finish(middleware.ErrorAction, err)
 		return
 	}
 
 	for {
 		in, err := stream.Recv()
 		if err == io.EOF {
-			middleware.SendWebSocketMessage(conn, "message_complete", "{}")
-			log.Println("Stream completed successfully")
+			cs.finish("message_complete", nil)
+			slog.InfoContext(ctx, "Stream completed successfully")
 			break
 		}
 		if err != nil {
-			log.Printf("Error receiving stream: %v", err)
+			if errors.Is(ctx.Err(), context.Canceled) {
+				cs.finish("message_cancelled", nil)
+				slog.InfoContext(ctx, "Stream cancelled")
+				break
+			}
+			slog.ErrorContext(ctx, "Error receiving stream", "err", err)
+			cs.finish(middleware.ErrorAction, err)
 			break
 		}
 
-		log.Printf("Received message fragment: %v", in)
+		if chat := in.GetRenamedChat(); chat != nil {
+			renamed := cs.rename(chat)
+			if h.Notifier != nil {
+				h.Notifier.SendToUser(cs.userID, "chat_renamed", renamed)
+			}
+			continue
+		}
 
-		middleware.SendWebSocketMessage(conn, "message_fragment", in)
+		slog.DebugContext(ctx, "Received message fragment", "message_id", in.GetMessage().GetId(), "body", in.GetMessage().GetBody())
+
+		cs.publish(in)
+	}
+}
+
+// CancelMessageStream stops a running stream, identified either by its
+// stream_id or by the request_id of the start_message_stream on this socket.
+// The chat service persists whatever was generated so far as a cancelled
+// message.
+func (h *ChatHandler) CancelMessageStream(conn middleware.WSConn, requestID string, streamID string, streamRequestID string) {
+	cs := h.findStream(conn, streamID, streamRequestID)
+	if cs == nil {
+		middleware.SendWebSocketError(conn, "cancel_message_stream", requestID, status.Error(codes.NotFound, "no active stream found"))
+		return
 	}
+
+	cs.cancel()
+	middleware.SendWebSocketMessage(conn, "cancel_message_stream_resp", requestID, map[string]bool{"cancelled": true})
 }
 
-func (h *ChatHandler) GetChatSummaries(conn *websocket.Conn, jwt string, id uint32) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+// ResumeMessageStream replays what the client missed after fragment lastSeq
+// and, if the answer is still being generated, moves the stream onto conn.
+// A stream started on another gateway replica is unknown here; if the client
+// sends the message_id it saw, the stored answer is replayed instead.
+func (h *ChatHandler) ResumeMessageStream(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, streamID string, messageID uint32, lastSeq int) {
+	cs := h.findStream(conn, streamID, "")
+	if cs == nil {
+		if messageID == 0 {
+			middleware.SendWebSocketError(conn, "resume_message_stream", requestID, status.Errorf(codes.NotFound, "stream %s not found", streamID))
+			return
+		}
+		h.replayStoredMessage(ctx, conn, requestID, jwt, streamID, messageID)
+		return
+	}
+
+	if cs.attach(conn, requestID, lastSeq) {
+		return
+	}
+
+	// The stream has finished, so the stored message is the full answer.
+	cs.mu.Lock()
+	messageID = cs.messageID
+	offset := cs.offset(lastSeq)
+	seq := len(cs.ends)
+	cs.mu.Unlock()
+
+	middleware.SendWebSocketMessage(conn, "resume_message_stream_resp", requestID, StreamEvent{StreamID: cs.id, MessageID: messageID})
+
+	if messageID != 0 {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		resp, err := h.ChatClient.GetMessageByID(middleware.WithJWTMetadata(ctx, jwt), &proto.GetMessageByIDRequest{MessageId: messageID})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error getting stored message", "err", err)
+			middleware.SendWebSocketError(conn, "resume_message_stream", requestID, err)
+			return
+		}
+
+		stored := resp.GetMessage()
+		if offset > len(stored.GetBody()) {
+			offset = len(stored.GetBody())
+		}
+		if tail := stored.GetBody()[offset:]; tail != "" {
+			middleware.SendWebSocketMessage(conn, "message_fragment", requestID, StreamFragment{
+				StreamID: cs.id,
+				Seq:      seq,
+				Replay:   true,
+				Message: &proto.Message{
+					Id:     stored.GetId(),
+					ChatId: stored.GetChatId(),
+					Sender: stored.GetSender(),
+					Body:   tail,
+					Status: stored.GetStatus(),
+				},
+			})
+		}
+	}
+
+	cs.mu.Lock()
+	cs.sendFinal(conn, requestID)
+	cs.mu.Unlock()
+}
+
+// replayStoredMessage resumes a stream this replica never relayed from the
+// message the chat service stored. Without the stream's fragment boundaries
+// last_seq cannot be mapped onto the body, so the whole body is replayed with
+// Full set, followed by the frame the message's status implies. An answer
+// still streaming belongs to the replica that started it; the client resumes
+// again once it has ended.
+func (h *ChatHandler) replayStoredMessage(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, streamID string, messageID uint32) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	resp, err := h.ChatClient.GetMessageByID(middleware.WithJWTMetadata(ctx, jwt), &proto.GetMessageByIDRequest{MessageId: messageID})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting stored message", "err", err)
+		middleware.SendWebSocketError(conn, "resume_message_stream", requestID, err)
+		return
+	}
+	stored := resp.GetMessage()
+
+	middleware.SendWebSocketMessage(conn, "resume_message_stream_resp", requestID, StreamEvent{StreamID: streamID, MessageID: stored.GetId()})
+	middleware.SendWebSocketMessage(conn, "message_fragment", requestID, StreamFragment{
+		StreamID: streamID,
+		Replay:   true,
+		Full:     true,
+		Message:  stored,
+	})
+
+	final := StreamEvent{StreamID: streamID, MessageID: stored.GetId()}
+	switch stored.GetStatus() {
+	case messageStatusStreaming:
+		// Still owned by the replica that started it.
+	case messageStatusCancelled:
+		middleware.SendWebSocketMessage(conn, "message_cancelled", requestID, final)
+	case messageStatusFailed:
+		middleware.SendWebSocketError(conn, "start_message_stream", requestID, status.Error(codes.Unavailable, "the answer failed before it was complete"))
+	default:
+		middleware.SendWebSocketMessage(conn, "message_complete", requestID, final)
+	}
+}
+
+func (h *ChatHandler) addStream(cs *chatStream) {
+	h.streamsMu.Lock()
+	h.streams[cs.id] = cs
+	h.streamsMu.Unlock()
+
+	go func() {
+		<-cs.ctx.Done()
+		time.AfterFunc(streamRetention, func() {
+			h.streamsMu.Lock()
+			delete(h.streams, cs.id)
+			h.streamsMu.Unlock()
+		})
+	}()
+}
+
+// findStream looks a stream up by id, or by the request that started it on
+// conn. Streams belonging to other users are never returned.
+func (h *ChatHandler) findStream(conn middleware.WSConn, streamID string, startRequestID string) *chatStream {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+
+	if streamID != "" {
+		cs, ok := h.streams[streamID]
+		if !ok || cs.userID != conn.UserID() {
+			return nil
+		}
+		return cs
+	}
+
+	for _, cs := range h.streams {
+		cs.mu.Lock()
+		match := cs.conn == conn && cs.requestID == startRequestID
+		cs.mu.Unlock()
+		if match && startRequestID != "" {
+			return cs
+		}
+	}
+	return nil
+}
+
+func (h *ChatHandler) GetChatSummaries(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, id uint32, includeArchived bool) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
+
+	resp, err := h.ChatClient.GetChatSummariesUID(ctxWithMetadata, &proto.GetChatSummariesUIDRequest{Id: id, IncludeArchived: includeArchived})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create message", "err", err)
+		middleware.SendWebSocketError(conn, "get_chat_summaries", requestID, err)
+		return
+	}
+
+	middleware.SendWebSocketMessage(conn, "get_chat_summaries_resp", requestID, resp)
+}
+
+func (h *ChatHandler) RenameChat(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, req *proto.RenameChatRequest) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
-	resp, err := h.ChatClient.GetChatSummariesUID(ctxWithMetadata, &proto.GetChatSummariesUIDRequest{Id: id})
+	resp, err := h.ChatClient.RenameChat(ctxWithMetadata, req)
 	if err != nil {
-		log.Printf("Failed to create message: %v", err)
+		slog.ErrorContext(ctx, "Error renaming chat", "err", err)
+		middleware.SendWebSocketError(conn, "rename_chat", requestID, err)
 		return
 	}
+	middleware.SendWebSocketMessage(conn, "rename_chat_resp", requestID, resp)
+}
+
+func (h *ChatHandler) PinChat(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, req *proto.PinChatRequest) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
+
+	resp, err := h.ChatClient.PinChat(ctxWithMetadata, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error pinning chat", "err", err)
+		middleware.SendWebSocketError(conn, "pin_chat", requestID, err)
+		return
+	}
+	middleware.SendWebSocketMessage(conn, "pin_chat_resp", requestID, resp)
+}
+
+func (h *ChatHandler) ArchiveChat(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, req *proto.ArchiveChatRequest) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
-	log.Printf("chat sums: %v", resp)
-	middleware.SendWebSocketMessage(conn, "get_chat_summaries_resp", resp)
+	resp, err := h.ChatClient.ArchiveChat(ctxWithMetadata, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error archiving chat", "err", err)
+		middleware.SendWebSocketError(conn, "archive_chat", requestID, err)
+		return
+	}
+	middleware.SendWebSocketMessage(conn, "archive_chat_resp", requestID, resp)
+}
+
+func (h *ChatHandler) SearchMessages(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, req *proto.SearchMessagesRequest) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
+
+	resp, err := h.ChatClient.SearchMessages(ctxWithMetadata, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error searching messages", "err", err)
+		middleware.SendWebSocketError(conn, "search_messages", requestID, err)
+		return
+	}
+	middleware.SendWebSocketMessage(conn, "search_messages_resp", requestID, resp)
 }
 
-func (h *ChatHandler) GetRecentMessages(conn *websocket.Conn, chatID uint32, lastMsgID uint32, limit uint32, jwt string) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *ChatHandler) GetRecentMessages(ctx context.Context, conn middleware.WSConn, requestID string, chatID uint32, lastMsgID uint32, limit uint32, jwt string) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.ChatClient.GetRecentMessages(ctxWithMetadata, &proto.GetRecentMessagesRequest{ChatId: chatID, LastMessageId: lastMsgID, Limit: limit})
 	if err != nil {
-		log.Printf("Error getting recent messages: %v", err)
+		slog.ErrorContext(ctx, "Error getting recent messages", "err", err)
+		middleware.SendWebSocketError(conn, "get_recent_messages", requestID, err)
 		return
 	}
 
-	log.Printf("Get recent msgs: %v", resp)
-	middleware.SendWebSocketMessage(conn, "get_recent_messages_resp", resp)
+	middleware.SendWebSocketMessage(conn, "get_recent_messages_resp", requestID, resp)
 }
 
-func (h *ChatHandler) DeleteChatByID(conn *websocket.Conn, jwt string, userID, chatID uint32) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *ChatHandler) DeleteChatByID(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, userID, chatID uint32) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.ChatClient.DeleteChatByID(ctxWithMetadata, &proto.DeleteChatByIDRequest{ChatId: chatID, UserId: userID})
 	if err != nil {
-		log.Printf("Error deleting chats by chatid: %v", err)
+		slog.ErrorContext(ctx, "Error deleting chats by chatid", "err", err)
+		middleware.SendWebSocketError(conn, "delete_chat_by_chat_id", requestID, err)
 		return
 	}
-	middleware.SendWebSocketMessage(conn, "delete_chat_by_chat_id_resp", resp)
+	middleware.SendWebSocketMessage(conn, "delete_chat_by_chat_id_resp", requestID, resp)
 }
 
-func (h *ChatHandler) DeleteAllChatsByUID(conn *websocket.Conn, jwt string, userID uint32) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *ChatHandler) DeleteAllChatsByUID(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, userID uint32) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.ChatClient.DeleteAllChatsByUID(ctxWithMetadata, &proto.DeleteAllChatsByUIDRequest{UserId: userID})
 	if err != nil {
-		log.Printf("Error deleting chats by UID: %v", err)
+		slog.ErrorContext(ctx, "Error deleting chats by UID", "err", err)
+		middleware.SendWebSocketError(conn, "delete_all_chats_by_user_id", requestID, err)
 		return
 	}
-	middleware.SendWebSocketMessage(conn, "delete_all_chats_by_user_id_resp", resp)
+	middleware.SendWebSocketMessage(conn, "delete_all_chats_by_user_id_resp", requestID, resp)
 }
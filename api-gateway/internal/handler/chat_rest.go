@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"api-gateway/pkg/proto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *ChatHandler) ListChats(ctx *gin.Context) {
+	includeArchived, ok := queryBool(ctx, "include_archived")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.GetChatSummariesUID(rpcCtx, &proto.GetChatSummariesUIDRequest{Id: restUserID(ctx), IncludeArchived: includeArchived})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) CreateChat(ctx *gin.Context) {
+	var req proto.CreateChatRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.CreateChat(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) SetChatName(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.RenameChatRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.ChatId = chatID
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.RenameChat(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) SetChatPinned(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.PinChatRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.ChatId = chatID
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.PinChat(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) SetChatArchived(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.ArchiveChatRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.ChatId = chatID
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.ArchiveChat(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) DeleteChat(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.DeleteChatByID(rpcCtx, &proto.DeleteChatByIDRequest{ChatId: chatID, UserId: restUserID(ctx)})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) DeleteAllChats(ctx *gin.Context) {
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.DeleteAllChatsByUID(rpcCtx, &proto.DeleteAllChatsByUIDRequest{UserId: restUserID(ctx)})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) ListMessages(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	lastMessageID, ok := queryUint(ctx, "last_message_id")
+	if !ok {
+		return
+	}
+	limit, ok := queryUint(ctx, "limit")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.GetRecentMessages(rpcCtx, &proto.GetRecentMessagesRequest{ChatId: chatID, LastMessageId: lastMessageID, Limit: limit})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) CreateMessage(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.CreateMessageRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.ChatId = chatID
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.CreateMessage(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) FindMessages(ctx *gin.Context) {
+	limit, ok := queryUint(ctx, "limit")
+	if !ok {
+		return
+	}
+	offset, ok := queryUint(ctx, "offset")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.SearchMessages(rpcCtx, &proto.SearchMessagesRequest{Query: ctx.Query("query"), Limit: limit, Offset: offset})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *ChatHandler) GetMessage(ctx *gin.Context) {
+	messageID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.ChatClient.GetMessageByID(rpcCtx, &proto.GetMessageByIDRequest{MessageId: messageID})
+	writeResponse(ctx, resp, err)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"api-gateway/internal/middleware"
+	"api-gateway/pkg/proto"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeatPeriod keeps proxies from closing an idle event stream while
+// the model is still thinking.
+const sseHeartbeatPeriod = 15 * time.Second
+
+// StreamMessagesSSE relays an AI answer as Server-Sent Events: one
+// "fragment" event per chunk, "chat_renamed" when the chat got a generated
+// title, which the user's sockets receive as well, then "message_complete"
+// or "error". The request context is the
+// stream context, so a client that goes away cancels the generation and the
+// chat service keeps what was produced so far.
+func (h *ChatHandler) StreamMessagesSSE(ctx *gin.Context) {
+	chatID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.CreateMessageRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.ChatId = chatID
+
+	streamCtx, cancel := context.WithTimeout(ctx.Request.Context(), streamTimeout)
+	defer cancel()
+
+	stream, err := h.ChatClient.StreamMessages(middleware.WithJWTMetadata(streamCtx, ctx.GetString("jwt")))
+	if err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Could not start Stream", "err", err)
+		writeError(ctx, err)
+		return
+	}
+	if err := stream.Send(&req); err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Failed to send a message", "err", err)
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	fragments := make(chan *proto.CreateMessageResponse)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case fragments <- in:
+			case <-streamCtx.Done():
+				recvErr <- streamCtx.Err()
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeatPeriod)
+	defer heartbeat.Stop()
+
+	streamID := newStreamID()
+	seq := 0
+	var messageID uint32
+	for {
+		select {
+		case in := <-fragments:
+			if chat := in.GetRenamedChat(); chat != nil {
+				renamed := ChatRenamed{StreamID: streamID, ChatID: chat.GetId(), Name: chat.GetName()}
+				writeSSE(ctx, sse.Event{Event: "chat_renamed", Data: renamed})
+				if h.Notifier != nil {
+					h.Notifier.SendToUser(uint(restUserID(ctx)), "chat_renamed", renamed)
+				}
+				continue
+			}
+			seq++
+			messageID = in.GetMessage().GetId()
+			writeSSE(ctx, sse.Event{
+				Id:    strconv.Itoa(seq),
+				Event: "fragment",
+				Data:  StreamFragment{StreamID: streamID, Seq: seq, Message: in.GetMessage()},
+			})
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": keepalive\n\n")
+			ctx.Writer.Flush()
+		case err := <-recvErr:
+			if err == io.EOF {
+				writeSSE(ctx, sse.Event{Event: "message_complete", Data: StreamEvent{StreamID: streamID, MessageID: messageID}})
+				slog.InfoContext(ctx.Request.Context(), "Stream completed successfully")
+				return
+			}
+			if ctx.Request.Context().Err() != nil {
+				slog.InfoContext(ctx.Request.Context(), "Stream cancelled: client disconnected")
+				return
+			}
+			slog.ErrorContext(ctx.Request.Context(), "Error receiving stream", "err", err)
+			writeSSE(ctx, sse.Event{Event: middleware.ErrorAction, Data: middleware.NewWSError(restAction(ctx), err)})
+			return
+		}
+	}
+}
+
+func writeSSE(ctx *gin.Context, event sse.Event) {
+	if err := sse.Encode(ctx.Writer, event); err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Failed to write event", "err", err)
+		return
+	}
+	ctx.Writer.Flush()
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"api-gateway/internal/middleware"
+	"api-gateway/pkg/proto"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How long a stream keeps generating after its socket drops, waiting for
+	// the client to reconnect and resume it.
+	streamResumeWindow = 30 * time.Second
+
+	// How long a finished stream can still be resumed to fetch its tail.
+	streamRetention = 5 * time.Minute
+)
+
+// Statuses the chat service stores on an answer.
+const (
+	messageStatusStreaming = "streaming"
+	messageStatusCancelled = "cancelled"
+	messageStatusFailed    = "failed"
+)
+
+// StreamFragment is the payload of message_fragment frames. Seq starts at 1
+// and increases by one per fragment; a client that reconnects resumes with
+// the last Seq it received. Full marks a replay of the whole answer, sent
+// without a Seq when the stream ran on another gateway replica; it replaces
+// whatever the client has.
+type StreamFragment struct {
+	StreamID string         `json:"stream_id"`
+	Seq      int            `json:"seq"`
+	Replay   bool           `json:"replay,omitempty"`
+	Full     bool           `json:"full,omitempty"`
+	Message  *proto.Message `json:"message"`
+}
+
+// StreamEvent is the payload of the frames that open, resume and end a
+// stream.
+type StreamEvent struct {
+	StreamID  string `json:"stream_id"`
+	MessageID uint32 `json:"message_id,omitempty"`
+	Live      bool   `json:"live,omitempty"`
+}
+
+// ChatRenamed is the payload of chat_renamed frames, sent to every socket of
+// the user after the answer when the chat got a generated title.
+type ChatRenamed struct {
+	StreamID string `json:"stream_id"`
+	ChatID   uint32 `json:"chat_id"`
+	Name     string `json:"name"`
+}
+
+// chatStream is one AI answer being relayed from the chat service. It
+// outlives the socket that started it so a reconnecting client can pick up
+// where it left off.
+type chatStream struct {
+	id     string
+	userID uint
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	conn        middleware.WSConn
+	requestID   string
+	chatID      uint32
+	messageID   uint32
+	sender      string
+	body        strings.Builder
+	ends        []int
+	renamed     *ChatRenamed
+	finalAction string
+	finalErr    error
+	detachTimer *time.Timer
+}
+
+func newChatStream(ctx context.Context, cancel context.CancelFunc, conn middleware.WSConn, requestID string) *chatStream {
+	return &chatStream{
+		id:        newStreamID(),
+		userID:    conn.UserID(),
+		ctx:       ctx,
+		cancel:    cancel,
+		conn:      conn,
+		requestID: requestID,
+	}
+}
+
+func newStreamID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// publish records a fragment from the chat service and forwards it to the
+// attached socket, if any.
+func (s *chatStream) publish(resp *proto.CreateMessageResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := resp.GetMessage()
+	s.chatID = msg.GetChatId()
+	s.messageID = msg.GetId()
+	s.sender = msg.GetSender()
+	s.body.WriteString(msg.GetBody())
+	s.ends = append(s.ends, s.body.Len())
+
+	if s.conn != nil {
+		middleware.SendWebSocketMessage(s.conn, "message_fragment", s.requestID, StreamFragment{
+			StreamID: s.id,
+			Seq:      len(s.ends),
+			Message:  msg,
+		})
+	}
+}
+
+// rename records the chat's generated title, which the handler pushes to
+// all of the user's sockets, so a socket resuming the stream gets it again.
+func (s *chatStream) rename(chat *proto.Chat) *ChatRenamed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.renamed = &ChatRenamed{StreamID: s.id, ChatID: chat.GetId(), Name: chat.GetName()}
+	return s.renamed
+}
+
+// finish records how the stream ended and tells the attached socket.
+func (s *chatStream) finish(action string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finalAction = action
+	s.finalErr = err
+	if s.detachTimer != nil {
+		s.detachTimer.Stop()
+	}
+	if s.conn != nil {
+		s.sendFinal(s.conn, s.requestID)
+	}
+}
+
+func (s *chatStream) sendFinal(conn middleware.WSConn, requestID string) {
+	if s.finalErr != nil {
+		middleware.SendWebSocketError(conn, "start_message_stream", requestID, s.finalErr)
+		return
+	}
+	middleware.SendWebSocketMessage(conn, s.finalAction, requestID, StreamEvent{StreamID: s.id, MessageID: s.messageID})
+}
+
+// watch detaches the socket when it disconnects and cancels the generation
+// if nobody resumes it within streamResumeWindow.
+func (s *chatStream) watch(conn middleware.WSConn) {
+	select {
+	case <-conn.Context().Done():
+	case <-s.ctx.Done():
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != conn || s.finalAction != "" {
+		return
+	}
+	s.conn = nil
+	s.detachTimer = time.AfterFunc(streamResumeWindow, s.cancel)
+}
+
+// offset is the number of body bytes a client has seen after receiving
+// fragment lastSeq.
+func (s *chatStream) offset(lastSeq int) int {
+	if lastSeq <= 0 {
+		return 0
+	}
+	if lastSeq > len(s.ends) {
+		lastSeq = len(s.ends)
+	}
+	if lastSeq == 0 {
+		return 0
+	}
+	return s.ends[lastSeq-1]
+}
+
+// attach moves a still running stream onto conn, replaying everything after
+// lastSeq first. It reports false if the stream has already finished.
+func (s *chatStream) attach(conn middleware.WSConn, requestID string, lastSeq int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finalAction != "" {
+		return false
+	}
+	if s.detachTimer != nil {
+		s.detachTimer.Stop()
+		s.detachTimer = nil
+	}
+
+	middleware.SendWebSocketMessage(conn, "resume_message_stream_resp", requestID, StreamEvent{StreamID: s.id, MessageID: s.messageID, Live: true})
+	if tail := s.body.String()[s.offset(lastSeq):]; tail != "" {
+		middleware.SendWebSocketMessage(conn, "message_fragment", requestID, StreamFragment{
+			StreamID: s.id,
+			Seq:      len(s.ends),
+			Replay:   true,
+			Message: &proto.Message{
+				Id:     s.messageID,
+				ChatId: s.chatID,
+				Sender: s.sender,
+				Body:   tail,
+			},
+		})
+	}
+
+	if s.renamed != nil {
+		middleware.SendWebSocketMessage(conn, "chat_renamed", requestID, s.renamed)
+	}
+
+	s.conn = conn
+	s.requestID = requestID
+	go s.watch(conn)
+	return true
+}
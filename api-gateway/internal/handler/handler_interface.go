@@ -2,10 +2,18 @@ package handler
 
 import (
 	"api-gateway/internal/middleware"
+	"context"
 
-	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// MessageHandler handles one socket message. ctx carries the message's
+// trace span and is the parent of every backend call made for it.
 type MessageHandler interface {
-	ProcessMessage(conn *websocket.Conn, msg middleware.WSMessage)
+	ProcessMessage(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage)
+}
+
+func invalidPayload(err error) error {
+	return status.Errorf(codes.InvalidArgument, "invalid request payload: %v", err)
 }
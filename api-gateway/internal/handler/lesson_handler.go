@@ -6,25 +6,36 @@ import (
 	"api-gateway/pkg/proto"
 	"context"
 	"encoding/json"
-	"log"
-	"time"
+	"log/slog"
 
-	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// lessonEvents maps actions whose results are pushed to all of the user's
+// devices to the event name the other devices receive.
+var lessonEvents = map[string]string{
+	"generate_topic_plan": "topic_plan_generated",
+	"generate_lessons":    "lessons_generated",
+	"generate_test":       "test_generated",
+	"grade_test":          "test_graded",
+}
+
 type LessonHandler struct {
 	Config         *config.Config
 	LessonClient   proto.LessonServiceClient
-	actionHandlers map[string]func(conn *websocket.Conn, jwt string, data []byte)
+	Notifier       middleware.UserNotifier
+	actionHandlers map[string]func(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage)
 }
 
-func NewLessonHandler(cfg *config.Config, lessonClient proto.LessonServiceClient) *LessonHandler {
+func NewLessonHandler(cfg *config.Config, lessonClient proto.LessonServiceClient, notifier middleware.UserNotifier) *LessonHandler {
 	h := &LessonHandler{
 		Config:       cfg,
 		LessonClient: lessonClient,
+		Notifier:     notifier,
 	}
 
-	h.actionHandlers = map[string]func(conn *websocket.Conn, jwt string, data []byte){
+	h.actionHandlers = map[string]func(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage){
 		"generate_topic_plan":              h.handleGenerateTopicPlan,
 		"generate_lessons":                 h.handleGenerateLessons,
 		"generate_test":                    h.handleGenerateTest,
@@ -41,115 +52,121 @@ func NewLessonHandler(cfg *config.Config, lessonClient proto.LessonServiceClient
 	return h
 }
 
-func (h *LessonHandler) ProcessMessage(conn *websocket.Conn, msg middleware.WSMessage) {
-	log.Printf("Raw data: %s", string(msg.Data))
+func (h *LessonHandler) ProcessMessage(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
+	slog.DebugContext(ctx, "Lesson action payload", "action", msg.Action, "payload", string(msg.Data))
 
 	handlerFunc, ok := h.actionHandlers[msg.Action]
 	if !ok {
-		log.Printf("Unknown action: %s", msg.Action)
+		slog.WarnContext(ctx, "Unknown lesson action", "action", msg.Action)
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, status.Errorf(codes.Unimplemented, "unknown lesson action: %s", msg.Action))
 		return
 	}
-	handlerFunc(conn, msg.JWT, msg.Data)
+	handlerFunc(ctx, conn, msg)
 }
 
 // Generic handler function to reduce repetition
-func (h *LessonHandler) handleAction(conn *websocket.Conn, jwt string, data []byte, req interface{}, serviceFunc func(ctx context.Context, req interface{}) (interface{}, error), respAction string) {
-	if err := json.Unmarshal(data, req); err != nil {
-		log.Printf("Failed to unmarshal %T: %v", req, err)
+func (h *LessonHandler) handleAction(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage, req interface{}, serviceFunc func(ctx context.Context, req interface{}) (interface{}, error), respAction string) {
+	if err := json.Unmarshal(msg.Data, req); err != nil {
+		slog.WarnContext(ctx, "Failed to unmarshal lesson request", "action", msg.Action, "err", err)
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
 		return
 	}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-		defer cancel()
+	// The backend connection applies the deadline, which is longer for
+	// generation than for reads.
+	ctxWithMetadata := middleware.WithJWTMetadata(ctx, msg.JWT)
 
-		ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
+	resp, err := serviceFunc(ctxWithMetadata, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error in service method", "err", err)
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, err)
+		return
+	}
 
-		resp, err := serviceFunc(ctxWithMetadata, req)
-		if err != nil {
-			log.Printf("Error in service method: %v", err)
-			return
-		}
+	middleware.SendWebSocketMessage(conn, respAction, msg.RequestID, resp)
 
-		middleware.SendWebSocketMessage(conn, respAction, resp)
-	}()
+	// conn has the result as its reply; the user's other devices get the
+	// event.
+	if event, ok := lessonEvents[msg.Action]; ok && h.Notifier != nil {
+		h.Notifier.SendToUserExcept(conn.UserID(), conn, event, resp)
+	}
 }
 
 // Individual handler functions
 
-func (h *LessonHandler) handleGenerateTopicPlan(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGenerateTopicPlan(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GenerateTopicPlanRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GenerateTopicPlan(ctx, req.(*proto.GenerateTopicPlanRequest))
 	}, "generate_topic_plan_resp")
 }
 
-func (h *LessonHandler) handleGenerateLessons(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGenerateLessons(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GenerateLessonsRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GenerateLessons(ctx, req.(*proto.GenerateLessonsRequest))
 	}, "generate_lessons_resp")
 }
 
-func (h *LessonHandler) handleGenerateTest(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGenerateTest(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GenerateTestRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GenerateTests(ctx, req.(*proto.GenerateTestRequest))
 	}, "generate_test_resp")
 }
 
-func (h *LessonHandler) handleGradeTest(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGradeTest(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GradeTestRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GradeTest(ctx, req.(*proto.GradeTestRequest))
 	}, "grade_test_resp")
 }
 
-func (h *LessonHandler) handleGetAllTopicPlansByUID(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGetAllTopicPlansByUID(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GetAllTopicPlansByUIDRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GetAllTopicPlansByUID(ctx, req.(*proto.GetAllTopicPlansByUIDRequest))
 	}, "get_all_topic_plans_by_uid_resp")
 }
 
-func (h *LessonHandler) handleGetAllLessonsByTopicID(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGetAllLessonsByTopicID(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GetAllLessonPlansByTopicIDRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GetAllLessonPlansByTopicID(ctx, req.(*proto.GetAllLessonPlansByTopicIDRequest))
 	}, "get_all_lesson_plans_by_topic_id_resp")
 }
 
-func (h *LessonHandler) handleGetAllTestsByLessonID(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGetAllTestsByLessonID(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GetAllTestsByLessonIDRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GetAllTestsByLessonID(ctx, req.(*proto.GetAllTestsByLessonIDRequest))
 	}, "get_all_tests_by_lesson_id_resp")
 }
 
-func (h *LessonHandler) handleGetLessonByID(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGetLessonByID(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GetLessonByIDRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GetLessonByID(ctx, req.(*proto.GetLessonByIDRequest))
 	}, "get_lesson_by_id_resp")
 }
 
-func (h *LessonHandler) handleGetAllQuestionsByTestID(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGetAllQuestionsByTestID(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GetAllQuestionsByTestIDRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GetAllQuestionsByTestID(ctx, req.(*proto.GetAllQuestionsByTestIDRequest))
 	}, "get_all_questions_by_test_id_resp")
 }
 
-func (h *LessonHandler) handleGenerateQuickResponse(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGenerateQuickResponse(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GenerateQuickResponseRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GenerateQuickResponse(ctx, req.(*proto.GenerateQuickResponseRequest))
 	}, "generate_topic_plan_overview_resp")
 }
 
-func (h *LessonHandler) handleGetTopicPlanByID(conn *websocket.Conn, jwt string, data []byte) {
+func (h *LessonHandler) handleGetTopicPlanByID(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	var req proto.GetTopicPlanByIDRequest
-	h.handleAction(conn, jwt, data, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
+	h.handleAction(ctx, conn, msg, &req, func(ctx context.Context, req interface{}) (interface{}, error) {
 		return h.LessonClient.GetTopicPlanByID(ctx, req.(*proto.GetTopicPlanByIDRequest))
 	}, "get_topic_plan_by_id_resp")
 }
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"api-gateway/pkg/proto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *LessonHandler) GenerateTopicPlanOverview(ctx *gin.Context) {
+	var req proto.GenerateQuickResponseRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GenerateQuickResponse(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) GenerateTopicPlan(ctx *gin.Context) {
+	var req proto.GenerateTopicPlanRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.UserId = restUserID(ctx)
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GenerateTopicPlan(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) ListTopicPlans(ctx *gin.Context) {
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GetAllTopicPlansByUID(rpcCtx, &proto.GetAllTopicPlansByUIDRequest{UserId: restUserID(ctx)})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) GetTopicPlan(ctx *gin.Context) {
+	topicPlanID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GetTopicPlanByID(rpcCtx, &proto.GetTopicPlanByIDRequest{TopicPlanId: topicPlanID})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) ListLessons(ctx *gin.Context) {
+	topicPlanID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GetAllLessonPlansByTopicID(rpcCtx, &proto.GetAllLessonPlansByTopicIDRequest{TopicPlanId: topicPlanID})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) GenerateLessons(ctx *gin.Context) {
+	topicPlanID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GenerateLessons(rpcCtx, &proto.GenerateLessonsRequest{TopicPlanId: topicPlanID})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) GetLesson(ctx *gin.Context) {
+	lessonID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GetLessonByID(rpcCtx, &proto.GetLessonByIDRequest{LessonId: lessonID})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) ListTests(ctx *gin.Context) {
+	lessonID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GetAllTestsByLessonID(rpcCtx, &proto.GetAllTestsByLessonIDRequest{LessonId: lessonID})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) GenerateTest(ctx *gin.Context) {
+	lessonID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.GenerateTestRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.LessonId = lessonID
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GenerateTests(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) ListQuestions(ctx *gin.Context) {
+	testID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GetAllQuestionsByTestID(rpcCtx, &proto.GetAllQuestionsByTestIDRequest{TestId: testID})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *LessonHandler) GradeTest(ctx *gin.Context) {
+	testID, ok := pathID(ctx, "id")
+	if !ok {
+		return
+	}
+	var req proto.GradeTestRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.TestId = testID
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.LessonClient.GradeTest(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"api-gateway/internal/middleware"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// restContext derives the outgoing gRPC context for a REST request from the
+// token JWTAuthMiddleware verified. Deadlines come from the backend
+// connection; the call is cancelled if the client goes away.
+func restContext(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	rpcCtx, cancel := context.WithCancel(ctx.Request.Context())
+	return middleware.WithJWTMetadata(rpcCtx, ctx.GetString("jwt")), cancel
+}
+
+// restUserID is the authenticated caller set by JWTAuthMiddleware.
+func restUserID(ctx *gin.Context) uint32 {
+	userID, _ := ctx.Get("userID")
+	id, _ := userID.(uint)
+	return uint32(id)
+}
+
+func pathID(ctx *gin.Context, name string) (uint32, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		writeError(ctx, status.Errorf(codes.InvalidArgument, "invalid %s: %q", name, ctx.Param(name)))
+		return 0, false
+	}
+	return uint32(id), true
+}
+
+func queryUint(ctx *gin.Context, name string) (uint32, bool) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return 0, true
+	}
+	value, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		writeError(ctx, status.Errorf(codes.InvalidArgument, "invalid %s: %q", name, raw))
+		return 0, false
+	}
+	return uint32(value), true
+}
+
+func queryBool(ctx *gin.Context, name string) (bool, bool) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		writeError(ctx, status.Errorf(codes.InvalidArgument, "invalid %s: %q", name, raw))
+		return false, false
+	}
+	return value, true
+}
+
+func bindJSON(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		writeError(ctx, invalidPayload(err))
+		return false
+	}
+	return true
+}
+
+func writeResponse(ctx *gin.Context, resp interface{}, err error) {
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// writeError renders a gRPC error with the same fields as a WebSocket error
+// frame and the closest HTTP status.
+func writeError(ctx *gin.Context, err error) {
+	st := status.Convert(err)
+	wsErr := middleware.NewWSError(restAction(ctx), err)
+	if wsErr.RetryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(wsErr.RetryAfter))
+	}
+	ctx.AbortWithStatusJSON(httpStatusFromCode(st.Code()), wsErr)
+}
+
+// restAction names the endpoint in error bodies. Custom-method routes are
+// dispatched through a wildcard segment, so the router records their
+// declared path under "route".
+func restAction(ctx *gin.Context) string {
+	if route := ctx.GetString("route"); route != "" {
+		return ctx.Request.Method + " " + route
+	}
+	return ctx.Request.Method + " " + ctx.FullPath()
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
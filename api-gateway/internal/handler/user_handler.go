@@ -2,19 +2,20 @@ package handler
 
 import (
 	"api-gateway/internal/config"
+	"api-gateway/internal/logging"
 	"api-gateway/internal/middleware"
 	"api-gateway/pkg/proto"
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var dataType = "application/json"
@@ -22,115 +23,124 @@ var dataType = "application/json"
 type UserHandler struct {
 	Config     *config.Config
 	UserClient proto.UserServiceClient
+	HTTPClient *http.Client
 }
 
-func NewUserHandler(cfg *config.Config, userClient proto.UserServiceClient) *UserHandler {
+func NewUserHandler(cfg *config.Config, userClient proto.UserServiceClient, httpClient *http.Client) *UserHandler {
 	return &UserHandler{
 		Config:     cfg,
 		UserClient: userClient,
+		HTTPClient: httpClient,
 	}
 }
 
-func (h *UserHandler) ProcessMessage(conn *websocket.Conn, msg middleware.WSMessage) {
-	log.Printf("Raw data: %s", string(msg.Data))
+func (h *UserHandler) ProcessMessage(ctx context.Context, conn middleware.WSConn, msg middleware.WSMessage) {
 	switch msg.Action {
 	case "get_user_info":
 		var getUserInfo proto.GetUserInfoRequest
 		if err := json.Unmarshal(msg.Data, &getUserInfo); err != nil {
-			log.Printf("Failed to unmarshal GetUserInfoRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal GetUserInfoRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		h.GetUserInfo(conn, msg.JWT, getUserInfo.Id)
+		h.GetUserInfo(ctx, conn, msg.RequestID, msg.JWT, getUserInfo.Id)
 	case "update_user_info":
 		var updateInfo proto.UpdateUserInfoRequest
 		if err := json.Unmarshal(msg.Data, &updateInfo); err != nil {
-			log.Printf("Failed to unmarshal UpdateUserInfoRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal UpdateUserInfoRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		h.UpdateUserInfo(conn, msg.JWT, updateInfo.Username, updateInfo.Email)
+		h.UpdateUserInfo(ctx, conn, msg.RequestID, msg.JWT, updateInfo.Username, updateInfo.Email)
 	case "search_for_user":
 		var search proto.SearchForUserRequest
 		if err := json.Unmarshal(msg.Data, &search); err != nil {
-			log.Printf("Failed to unmarshal SearchForUserRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal SearchForUserRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		h.SearchForUser(conn, msg.JWT, search.Username)
+		h.SearchForUser(ctx, conn, msg.RequestID, msg.JWT, search.Username)
 	case "verify_user_pass":
 		var verify proto.VerifyUserPasswordRequest
 		if err := json.Unmarshal(msg.Data, &verify); err != nil {
-			log.Printf("Failed to unmarshal VerifyUserPasswordRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal VerifyUserPasswordRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		h.VerifyUserPassword(conn, msg.JWT, verify.Password, uint(verify.Id))
+		h.VerifyUserPassword(ctx, conn, msg.RequestID, msg.JWT, verify.Password, uint(verify.Id))
 	case "update_user_pass":
 		var updatePassword proto.UpdateUserPasswordRequest
 		if err := json.Unmarshal(msg.Data, &updatePassword); err != nil {
-			log.Printf("Failed to unmarshal UpdateUserPasswordRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal UpdateUserPasswordRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		h.UpdateUserPassword(conn, msg.JWT, updatePassword.OldPass, updatePassword.Password, uint(updatePassword.Id))
+		h.UpdateUserPassword(ctx, conn, msg.RequestID, msg.JWT, updatePassword.OldPass, updatePassword.Password, uint(updatePassword.Id))
 	case "delete_user":
 		var DeleteUser proto.DeleteUserRequest
 		if err := json.Unmarshal(msg.Data, &DeleteUser); err != nil {
-			log.Printf("Failed to unmarshal DeleteUserRequest: %v", err)
+			slog.WarnContext(ctx, "Failed to unmarshal DeleteUserRequest", "err", err)
+			middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, invalidPayload(err))
+			return
 		}
-		h.DeleteUser(conn, msg.JWT, DeleteUser.Password, uint(DeleteUser.Id))
+		h.DeleteUser(ctx, conn, msg.RequestID, msg.JWT, DeleteUser.Password, uint(DeleteUser.Id))
 	default:
-		log.Print("Invalid action type")
+		slog.WarnContext(ctx, "Unknown user action", "action", msg.Action)
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, status.Errorf(codes.Unimplemented, "unknown user action: %s", msg.Action))
 	}
 
 }
 
-func (h *UserHandler) GetUserInfo(conn *websocket.Conn, jwt string, userId uint32) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *UserHandler) GetUserInfo(ctx context.Context, conn middleware.WSConn, requestID string, jwt string, userId uint32) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.UserClient.GetUserInfo(ctxWithMetadata, &proto.GetUserInfoRequest{Id: userId})
 	if err != nil {
-		log.Printf("failed to create message: %v", err)
+		slog.ErrorContext(ctx, "Failed to create message", "err", err)
+		middleware.SendWebSocketError(conn, "get_user_info", requestID, err)
 		return
 	}
 
-	log.Printf("user info: %v", resp)
-	middleware.SendWebSocketMessage(conn, "get_user_info_resp", resp)
+	middleware.SendWebSocketMessage(conn, "get_user_info_resp", requestID, resp)
 
 }
 
-func (h *UserHandler) UpdateUserInfo(conn *websocket.Conn, jwt, username, email string) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *UserHandler) UpdateUserInfo(ctx context.Context, conn middleware.WSConn, requestID string, jwt, username, email string) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.UserClient.UpdateUserInfo(ctxWithMetadata, &proto.UpdateUserInfoRequest{Username: username, Email: email})
 	if err != nil {
-		log.Print("Failed to create UpdateUserMessage")
+		slog.ErrorContext(ctx, "Failed to create UpdateUserMessage", "err", err)
+		middleware.SendWebSocketError(conn, "update_user_info", requestID, err)
 		return
 	}
 
-	userInfo, err := json.Marshal(resp)
-	if err != nil {
-		log.Printf("failed to marshal the userinfo to JSON: %v", err)
-		return
-	}
-	if err := conn.WriteMessage(websocket.TextMessage, userInfo); err != nil {
-		log.Printf("Error sending userinfo over WS connnection: %v", err)
-	}
+	middleware.SendWebSocketMessage(conn, "update_user_info_resp", requestID, resp)
 }
 
-func (h *UserHandler) VerifyUserPassword(conn *websocket.Conn, jwt, password string, userID uint) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *UserHandler) VerifyUserPassword(ctx context.Context, conn middleware.WSConn, requestID string, jwt, password string, userID uint) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.UserClient.VerifyUserPassword(ctxWithMetadata, &proto.VerifyUserPasswordRequest{Id: uint32(userID), Password: password})
 	if err != nil {
-		log.Print("Failed to create verify password message")
+		slog.ErrorContext(ctx, "Failed to create verify password message", "err", err)
+		middleware.SendWebSocketError(conn, "verify_user_pass", requestID, err)
 		return
 	}
-	middleware.SendWebSocketMessage(conn, "verify_user_pass_resp", resp)
+	middleware.SendWebSocketMessage(conn, "verify_user_pass_resp", requestID, resp)
 }
 
-func (h *UserHandler) UpdateUserPassword(conn *websocket.Conn, jwt, oldPass, password string, userID uint) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *UserHandler) UpdateUserPassword(ctx context.Context, conn middleware.WSConn, requestID string, jwt, oldPass, password string, userID uint) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
@@ -138,47 +148,43 @@ func (h *UserHandler) UpdateUserPassword(conn *websocket.Conn, jwt, oldPass, pas
 	resp, err := h.UserClient.UpdateUserPassword(ctxWithMetadata, &proto.UpdateUserPasswordRequest{Id: uint32(userID), OldPass: oldPass, Password: password})
 
 	if err != nil {
-		log.Print("Failed to create update password message")
+		slog.ErrorContext(ctx, "Failed to create update password message", "err", err)
+		middleware.SendWebSocketError(conn, "update_user_pass", requestID, err)
 		return
 	}
-	middleware.SendWebSocketMessage(conn, "update_user_pass_resp", resp)
+	middleware.SendWebSocketMessage(conn, "update_user_pass_resp", requestID, resp)
 }
 
-func (h *UserHandler) DeleteUser(conn *websocket.Conn, jwt, password string, userID uint) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *UserHandler) DeleteUser(ctx context.Context, conn middleware.WSConn, requestID string, jwt, password string, userID uint) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.UserClient.DeleteUser(ctxWithMetadata, &proto.DeleteUserRequest{Id: uint32(userID), Password: password})
 	if err != nil {
-		log.Print("Failed to create delete message")
+		slog.ErrorContext(ctx, "Failed to create delete message", "err", err)
+		middleware.SendWebSocketError(conn, "delete_user", requestID, err)
 		return
 	}
-	middleware.SendWebSocketMessage(conn, "delete_user_resp", resp)
+	middleware.SendWebSocketMessage(conn, "delete_user_resp", requestID, resp)
 
 }
 
-func (h *UserHandler) SearchForUser(conn *websocket.Conn, jwt, username string) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+func (h *UserHandler) SearchForUser(ctx context.Context, conn middleware.WSConn, requestID string, jwt, username string) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	ctxWithMetadata := middleware.WithJWTMetadata(ctx, jwt)
 
 	resp, err := h.UserClient.SearchForUser(ctxWithMetadata, &proto.SearchForUserRequest{Username: username})
 	if err != nil {
-		log.Print("Failed to create UpdateUserMessage")
+		slog.ErrorContext(ctx, "Failed to create UpdateUserMessage", "err", err)
+		middleware.SendWebSocketError(conn, "search_for_user", requestID, err)
 		return
 	}
 
-	userName, err := json.Marshal(resp)
-	if err != nil {
-		log.Printf("failed to marshal the username to JSON: %v", err)
-		return
-	}
-	if err := conn.WriteMessage(websocket.TextMessage, userName); err != nil {
-		log.Printf("Error sending UserName over WS connnection: %v", err)
-	}
+	middleware.SendWebSocketMessage(conn, "search_for_user_resp", requestID, resp)
 }
 
 func (h *UserHandler) Login(ctx *gin.Context) {
@@ -198,7 +204,7 @@ func (h *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := http.Post(h.Config.UserHTTPServiceURL+"/login", dataType, bytes.NewBuffer(credsJSON))
+	resp, err := h.postToUserService(ctx.Request.Context(), "/login", credsJSON)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send request to user service"})
 		return
@@ -232,7 +238,7 @@ func (h *UserHandler) CreateUser(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := http.Post(h.Config.UserHTTPServiceURL+"/create_user", dataType, bytes.NewBuffer(actInfoJSON))
+	resp, err := h.postToUserService(ctx.Request.Context(), "/create_user", actInfoJSON)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failled to post to user service"})
 		return
@@ -253,61 +259,49 @@ func (h *UserHandler) RefreshToken(ctx *gin.Context) {
 		RefreshToken string `json:"refreshToken"`
 	}
 
-	// Log the incoming request
-	fmt.Println("Received request to refresh token")
-
-	// Log the raw request body
-	bodyBytes, err := io.ReadAll(ctx.Request.Body)
-	if err != nil {
-		fmt.Printf("Failed to read request body: %v\n", err)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
-		return
-	}
-	fmt.Printf("Raw request body: %s\n", string(bodyBytes))
-
-	// Restore the body for further processing
-	ctx.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
 	if err := ctx.ShouldBindJSON(&tokenRequest); err != nil {
-		fmt.Printf("Failed to bind JSON: %v\n", err)
+		slog.WarnContext(ctx.Request.Context(), "Failed to bind refresh token request", "err", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Log the received refresh token
-	fmt.Printf("Received refresh token: %s\n", tokenRequest.RefreshToken)
-
 	tokenJSON, err := json.Marshal(tokenRequest)
 	if err != nil {
-		fmt.Printf("Failed to marshal JSON: %v\n", err)
+		slog.ErrorContext(ctx.Request.Context(), "Failed to marshal refresh token request", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal JSON on refresh token"})
 		return
 	}
 
-	// Log the marshaled JSON
-	fmt.Printf("Marshaled JSON: %s\n", string(tokenJSON))
-
-	resp, err := http.Post(h.Config.UserHTTPServiceURL+"/refresh_token", dataType, bytes.NewBuffer(tokenJSON))
+	resp, err := h.postToUserService(ctx.Request.Context(), "/refresh_token", tokenJSON)
 	if err != nil {
-		fmt.Printf("Failed to reach user service: %v\n", err)
+		slog.ErrorContext(ctx.Request.Context(), "Failed to reach user service", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reach user service"})
 		return
 	}
-
 	defer resp.Body.Close()
 
-	// Log the response status
-	fmt.Printf("User service response status: %d\n", resp.StatusCode)
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("Failed to read response body: %v\n", err)
+		slog.ErrorContext(ctx.Request.Context(), "Failed to read user service response", "err", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from user service"})
 		return
 	}
 
-	// Log the response body
-	fmt.Printf("User service response body: %s\n", string(body))
-
+	slog.InfoContext(ctx.Request.Context(), "Refreshed token", "status", resp.StatusCode)
 	ctx.Data(resp.StatusCode, dataType, body)
 }
+
+// postToUserService forwards a JSON body to the user service's HTTP API
+// under the caller's request context, so the call joins its trace and
+// carries its request ID.
+func (h *UserHandler) postToUserService(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.Config.UserHTTPServiceURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dataType)
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.MetadataKey, id)
+	}
+	return h.HTTPClient.Do(req)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"api-gateway/pkg/proto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *UserHandler) GetCurrentUser(ctx *gin.Context) {
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.UserClient.GetUserInfo(rpcCtx, &proto.GetUserInfoRequest{Id: restUserID(ctx)})
+	writeResponse(ctx, resp, err)
+}
+
+func (h *UserHandler) UpdateCurrentUser(ctx *gin.Context) {
+	var req proto.UpdateUserInfoRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.Id = restUserID(ctx)
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.UserClient.UpdateUserInfo(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *UserHandler) UpdateCurrentUserPassword(ctx *gin.Context) {
+	var req proto.UpdateUserPasswordRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.Id = restUserID(ctx)
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.UserClient.UpdateUserPassword(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *UserHandler) VerifyCurrentUserPassword(ctx *gin.Context) {
+	var req proto.VerifyUserPasswordRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.Id = restUserID(ctx)
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.UserClient.VerifyUserPassword(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *UserHandler) DeleteCurrentUser(ctx *gin.Context) {
+	var req proto.DeleteUserRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	req.Id = restUserID(ctx)
+
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.UserClient.DeleteUser(rpcCtx, &req)
+	writeResponse(ctx, resp, err)
+}
+
+func (h *UserHandler) SearchUsers(ctx *gin.Context) {
+	rpcCtx, cancel := restContext(ctx)
+	defer cancel()
+
+	resp, err := h.UserClient.SearchForUser(rpcCtx, &proto.SearchForUserRequest{Username: ctx.Query("username")})
+	writeResponse(ctx, resp, err)
+}
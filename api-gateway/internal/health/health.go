@@ -0,0 +1,111 @@
+package health
+
+import (
+	"api-gateway/internal/backend"
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const probeTimeout = 2 * time.Second
+
+type BackendStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the body of /readyz. Status is "ok" when every backend is
+// serving, "degraded" when only some are, and "unavailable" when none are.
+// A gateway that is shutting down reports "draining".
+type Report struct {
+	Status   string                   `json:"status"`
+	Backends map[string]BackendStatus `json:"backends"`
+}
+
+// Prober asks each backend's grpc.health.v1 service how it is doing.
+type Prober struct {
+	backends []*backend.Backend
+	draining atomic.Bool
+}
+
+func NewProber(backends ...*backend.Backend) *Prober {
+	return &Prober{backends: backends}
+}
+
+// SetDraining makes /readyz fail so load balancers stop sending new
+// connections while the gateway shuts down.
+func (p *Prober) SetDraining() {
+	p.draining.Store(true)
+}
+
+func (p *Prober) Check(ctx context.Context) Report {
+	if p.draining.Load() {
+		return Report{Status: "draining"}
+	}
+
+	report := Report{Backends: make(map[string]BackendStatus, len(p.backends))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, b := range p.backends {
+		wg.Add(1)
+		go func(b *backend.Backend) {
+			defer wg.Done()
+			result := probe(ctx, b)
+			mu.Lock()
+			report.Backends[b.Name] = result
+			mu.Unlock()
+		}(b)
+	}
+	wg.Wait()
+
+	serving := 0
+	for _, result := range report.Backends {
+		if result.Status == healthpb.HealthCheckResponse_SERVING.String() {
+			serving++
+		}
+	}
+	switch serving {
+	case len(p.backends):
+		report.Status = "ok"
+	case 0:
+		report.Status = "unavailable"
+	default:
+		report.Status = "degraded"
+	}
+	return report
+}
+
+func probe(ctx context.Context, b *backend.Backend) BackendStatus {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(b.Conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return BackendStatus{Status: healthpb.HealthCheckResponse_UNKNOWN.String(), Error: status.Convert(err).Message()}
+	}
+	return BackendStatus{Status: resp.GetStatus().String()}
+}
+
+// Liveness answers /healthz: the process is up and serving HTTP.
+func (p *Prober) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness answers /readyz. A degraded gateway stays ready because the
+// backends that are up still work; it only reports 503 when none are or the
+// gateway is draining.
+func (p *Prober) Readiness(ctx *gin.Context) {
+	report := p.Check(ctx.Request.Context())
+	code := http.StatusOK
+	if report.Status == "unavailable" || report.Status == "draining" {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
+}
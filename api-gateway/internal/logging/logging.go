@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MetadataKey carries the request ID between services, both as gRPC
+// metadata and as an HTTP header.
+const MetadataKey = "x-request-id"
+
+const redacted = "[REDACTED]"
+
+// secretKeys are attribute keys whose values are never logged.
+var secretKeys = map[string]bool{
+	"password":      true,
+	"old_password":  true,
+	"new_password":  true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"jwt":           true,
+	"authorization": true,
+	"secret":        true,
+	"api_key":       true,
+}
+
+// contentKeys are attribute keys holding what users wrote or what the model
+// answered. They are redacted unless Config.RedactContent is off.
+var contentKeys = map[string]bool{
+	"body":       true,
+	"prompt":     true,
+	"content":    true,
+	"completion": true,
+	"payload":    true,
+}
+
+type Config struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// Format is json or text.
+	Format string
+	// RedactContent hides user and model content, see contentKeys.
+	RedactContent bool
+}
+
+// Setup makes a redacting, request-aware slog logger the default. Output
+// from the standard log package goes through it as well.
+func Setup(service string, cfg Config) *slog.Logger {
+	logger := slog.New(NewHandler(os.Stdout, cfg)).With("service", service)
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	return logger
+}
+
+func NewHandler(w io.Writer, cfg Config) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level:       parseLevel(cfg.Level),
+		ReplaceAttr: redactor(cfg.RedactContent),
+	}
+	var h slog.Handler
+	if cfg.Format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return contextHandler{h}
+}
+
+func parseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func redactor(redactContent bool) func([]string, slog.Attr) slog.Attr {
+	return func(_ []string, a slog.Attr) slog.Attr {
+		key := strings.ToLower(a.Key)
+		if secretKeys[key] {
+			return slog.String(a.Key, redacted)
+		}
+		if redactContent && contentKeys[key] {
+			return slog.String(a.Key, redacted+" "+strconv.Itoa(len(a.Value.String()))+" bytes")
+		}
+		return a
+	}
+}
+
+// contextHandler adds the request ID and the trace and span IDs from the
+// context passed to the *Context logging functions.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcClientHandling = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_handling_seconds",
+		Help:    "Time taken by backend gRPC calls, by method and status code. Streams are timed until they end.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 16),
+	}, []string{"grpc_method", "grpc_code"})
+
+	wsConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "websocket_connections_active",
+		Help: "Open WebSocket connections.",
+	})
+
+	wsActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "websocket_actions_total",
+		Help: "WebSocket messages received, by type, action and outcome (dispatched, rejected or rate_limited).",
+	}, []string{"type", "action", "outcome"})
+)
+
+// Serve exposes /metrics on its own listener so it stays off the public
+// HTTPS port.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		slog.Info("Metrics server is listening", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("Metrics server stopped", "err", err)
+		}
+	}()
+}
+
+func ConnectionOpened() {
+	wsConnections.Inc()
+}
+
+func ConnectionClosed() {
+	wsConnections.Dec()
+}
+
+// labelPattern keeps client-chosen type and action names from turning into
+// unbounded label values.
+var labelPattern = regexp.MustCompile(`^[a-z_]{1,64}$`)
+
+func ActionReceived(msgType, action, outcome string) {
+	if !labelPattern.MatchString(msgType) {
+		msgType = "invalid"
+	}
+	if !labelPattern.MatchString(action) {
+		action = "invalid"
+	}
+	wsActions.WithLabelValues(msgType, action, outcome).Inc()
+}
+
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		grpcClientHandling.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			grpcClientHandling.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+			return nil, err
+		}
+		return &timedStream{ClientStream: stream, method: method, start: start}, nil
+	}
+}
+
+// timedStream observes a stream once, when RecvMsg first reports its end.
+type timedStream struct {
+	grpc.ClientStream
+	method string
+	start  time.Time
+	once   sync.Once
+}
+
+func (s *timedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		code := status.Code(err)
+		if err == io.EOF {
+			code = codes.OK
+		}
+		s.once.Do(func() {
+			grpcClientHandling.WithLabelValues(s.method, code.String()).Observe(time.Since(s.start).Seconds())
+		})
+	}
+	return err
+}
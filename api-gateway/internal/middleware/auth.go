@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"api-gateway/internal/config"
+	"api-gateway/internal/logging"
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
@@ -13,7 +16,7 @@ import (
 
 func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if ctx.Request.URL.Path == "/api-v1/login" || ctx.Request.URL.Path == "/api-v1/create_user" || ctx.Request.URL.Path == "/api-v1/refresh_token" {
+		if ctx.Request.URL.Path == "/api-v1/login" || ctx.Request.URL.Path == "/api-v1/create_user" || ctx.Request.URL.Path == "/api-v1/refresh_token" || ctx.Request.URL.Path == "/api-v1/openapi.json" {
 			ctx.Next()
 			return
 		}
@@ -22,31 +25,68 @@ func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
 			return
 		}
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.NewValidationError("Unexpected signing method", jwt.ValidationErrorSignatureInvalid)
-			}
-			return []byte(cfg.AccessSecret), nil
-		})
 
+		userID, _, err := ParseAccessToken(authHeader, cfg.AccessSecret)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			ctx.Set("userID", claims["user_id"])
-		} else {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-		}
-
+		ctx.Set("userID", userID)
+		ctx.Set("jwt", strings.TrimPrefix(authHeader, "Bearer "))
 		ctx.Next()
 	}
 }
 
+// WithJWTMetadata builds the outgoing metadata for a backend call, including
+// the request ID of ctx. The backend connection's stats handler adds the
+// trace context of ctx to it.
 func WithJWTMetadata(ctx context.Context, jwt string) context.Context {
 	md := metadata.New(map[string]string{"authorization": "Bearer " + jwt})
+	if id := logging.RequestID(ctx); id != "" {
+		md.Set(logging.MetadataKey, id)
+	}
 	return metadata.NewOutgoingContext(ctx, md)
 }
+
+// ParseAccessToken validates an access token issued by the user service and
+// returns the user it belongs to and when it expires.
+func ParseAccessToken(tokenString, secret string) (uint, time.Time, error) {
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.NewValidationError("Unexpected signing method", jwt.ValidationErrorSignatureInvalid)
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, time.Time{}, errors.New("invalid token or claims type incorrect")
+	}
+
+	userID, ok := UserIDFromClaims(claims)
+	if !ok {
+		return 0, time.Time{}, errors.New("user_id claim missing or not a number")
+	}
+
+	var expiresAt time.Time
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+	return userID, expiresAt, nil
+}
+
+// UserIDFromClaims reads the user_id claim set by the user service, which
+// arrives as a JSON number.
+func UserIDFromClaims(claims jwt.MapClaims) (uint, bool) {
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return uint(userID), true
+}
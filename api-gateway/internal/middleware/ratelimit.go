@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"api-gateway/internal/ratelimit"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware applies policy to a REST route. It must run after
+// JWTAuthMiddleware so the caller is known.
+func RateLimitMiddleware(policy *ratelimit.Policy, action string, class ratelimit.Class) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, _ := ctx.Get("userID")
+		id, ok := userID.(uint)
+		if !ok {
+			return
+		}
+
+		if err := policy.Check(ctx.Request.Context(), id, action, class); err != nil {
+			wsErr := NewWSError(action, err)
+			ctx.Header("Retry-After", strconv.Itoa(wsErr.RetryAfter))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, wsErr)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"api-gateway/internal/logging"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger gives every HTTP request a request ID, taken from the
+// X-Request-ID header when the client sends one, and logs the request once
+// it is served. Query strings are left out since /wss carries the access
+// token in one.
+func RequestLogger() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(logging.MetadataKey)
+		if id == "" {
+			id = logging.NewRequestID()
+		}
+		reqCtx := logging.WithRequestID(ctx.Request.Context(), id)
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Header(logging.MetadataKey, id)
+
+		start := time.Now()
+		ctx.Next()
+
+		slog.InfoContext(reqCtx, "HTTP request served",
+			"method", ctx.Request.Method,
+			"path", ctx.Request.URL.Path,
+			"status", ctx.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds())
+	}
+}
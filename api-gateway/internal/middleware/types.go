@@ -1,10 +1,43 @@
 package middleware
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 type WSMessage struct {
-	Action string          `json:"action"`
-	Type   string          `json:"type"`
-	JWT    string          `json:"jwt"`
-	Data   json.RawMessage `json:"data"`
+	Action    string          `json:"action"`
+	Type      string          `json:"type"`
+	RequestID string          `json:"request_id,omitempty"`
+	JWT       string          `json:"jwt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WSConn is the outbound side of a client socket. Implementations must be
+// safe for concurrent use, since handlers reply from their own goroutines.
+type WSConn interface {
+	Send(msg []byte) error
+	UserID() uint
+	// Context is cancelled when the client disconnects.
+	Context() context.Context
+}
+
+// UserNotifier pushes server-initiated events to every live connection of a
+// user, e.g. so a lesson generated on one device shows up on the others.
+type UserNotifier interface {
+	SendToUser(userID uint, action string, data interface{}) int
+	// SendToUserExcept skips except, typically the connection that already
+	// got the result as its reply.
+	SendToUserExcept(userID uint, except WSConn, action string, data interface{}) int
+}
+
+// WSError is the payload of an "error" frame. Action is the action that
+// failed and Code is the gRPC status code name, e.g. "Unavailable".
+// RetryAfter is set, in seconds, when the request was rate limited.
+type WSError struct {
+	Action     string `json:"action"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable"`
+	RetryAfter int    `json:"retry_after,omitempty"`
 }
@@ -1,31 +1,62 @@
 package middleware
 
 import (
+	"api-gateway/internal/ratelimit"
 	"encoding/json"
-	"log"
+	"log/slog"
 
-	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func SendWebSocketMessage(conn *websocket.Conn, action string, data interface{}) {
+const ErrorAction = "error"
+
+func SendWebSocketMessage(conn WSConn, action, requestID string, data interface{}) {
 	msgData, err := json.Marshal(data)
 	if err != nil {
-		log.Printf("Error marshaling message to JSON: %v", err)
+		slog.Error("Error marshaling message to JSON", "err", err)
 		return
 	}
 
 	wsMsg := WSMessage{
-		Action: action,
-		Data:   json.RawMessage(msgData),
+		Action:    action,
+		RequestID: requestID,
+		Data:      json.RawMessage(msgData),
 	}
 
 	msg, err := json.Marshal(wsMsg)
 	if err != nil {
-		log.Printf("Error marshaling WebSocket message to JSON: %v", err)
+		slog.Error("Error marshaling WebSocket message to JSON", "err", err)
 		return
 	}
 
-	if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
-		log.Printf("Error sending WebSocket message: %v", err)
+	if err := conn.Send(msg); err != nil {
+		slog.Error("Error sending WebSocket message", "err", err)
+	}
+}
+
+// SendWebSocketError reports a failed action to the client as an "error"
+// frame. Errors that are not gRPC statuses are reported as Unknown.
+func SendWebSocketError(conn WSConn, action, requestID string, err error) {
+	SendWebSocketMessage(conn, ErrorAction, requestID, NewWSError(action, err))
+}
+
+func NewWSError(action string, err error) WSError {
+	st := status.Convert(err)
+	return WSError{
+		Action:     action,
+		Code:       st.Code().String(),
+		Message:    st.Message(),
+		Retryable:  isRetryable(st.Code()),
+		RetryAfter: ratelimit.RetryAfterSeconds(ratelimit.RetryAfter(err)),
+	}
+}
+
+func isRetryable(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
 	}
 }
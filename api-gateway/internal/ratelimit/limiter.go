@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate tokens
+// per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Per builds a Limit that allows n requests per interval with a burst of
+// burst.
+func Per(n int, interval time.Duration, burst int) Limit {
+	return Limit{Rate: float64(n) / interval.Seconds(), Burst: burst}
+}
+
+// Result is the outcome of taking one token. RetryAfter is how long until
+// a token is available again when the request was rejected.
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from named buckets. MemoryLimiter keeps buckets in
+// process; a shared store lets several gateway instances enforce the same
+// limits.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	// Refund gives back a token taken by Allow for a request that another
+	// limit then rejected.
+	Refund(ctx context.Context, key string, limit Limit) error
+}
+
+// bucketIdle is how long a full bucket is kept before it is dropped.
+const bucketIdle = 10 * time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true}, nil
+	}
+	if limit.Rate <= 0 {
+		return Result{RetryAfter: bucketIdle}, nil
+	}
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return Result{RetryAfter: wait}, nil
+}
+
+func (l *MemoryLimiter) Refund(ctx context.Context, key string, limit Limit) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+1)
+	}
+	return nil
+}
+
+// sweep drops buckets that have not been touched for bucketIdle; they would
+// have refilled by now and are recreated full on the next request.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdle {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= bucketIdle {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a MemoryLimiter clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestLimiter() (*MemoryLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewMemoryLimiter()
+	l.now = clock.Now
+	return l, clock
+}
+
+func allow(t *testing.T, l *MemoryLimiter, key string, limit Limit) Result {
+	t.Helper()
+	result, err := l.Allow(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	return result
+}
+
+func TestMemoryLimiterBurstAndRefill(t *testing.T) {
+	l, clock := newTestLimiter()
+	limit := Per(60, time.Minute, 3)
+
+	for i := 0; i < 3; i++ {
+		if !allow(t, l, "1:chat", limit).Allowed {
+			t.Fatalf("request %d of the burst rejected", i+1)
+		}
+	}
+	result := allow(t, l, "1:chat", limit)
+	if result.Allowed {
+		t.Fatal("request past the burst allowed")
+	}
+	if result.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", result.RetryAfter)
+	}
+	if !allow(t, l, "2:chat", limit).Allowed {
+		t.Error("another key shares the bucket")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if allow(t, l, "1:chat", limit).Allowed {
+		t.Error("allowed after half a token refilled")
+	}
+	clock.Advance(500 * time.Millisecond)
+	if !allow(t, l, "1:chat", limit).Allowed {
+		t.Error("rejected after a full token refilled")
+	}
+
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !allow(t, l, "1:chat", limit).Allowed {
+			t.Fatalf("request %d after a long pause rejected", i+1)
+		}
+	}
+	if allow(t, l, "1:chat", limit).Allowed {
+		t.Error("bucket refilled past its burst")
+	}
+}
+
+func TestMemoryLimiterRefund(t *testing.T) {
+	l, _ := newTestLimiter()
+	limit := Per(1, 24*time.Hour, 1)
+
+	allow(t, l, "1:chat", limit)
+	if err := l.Refund(context.Background(), "1:chat", limit); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if !allow(t, l, "1:chat", limit).Allowed {
+		t.Error("refunded token not available")
+	}
+
+	l.Refund(context.Background(), "1:chat", limit)
+	l.Refund(context.Background(), "1:chat", limit)
+	allow(t, l, "1:chat", limit)
+	if allow(t, l, "1:chat", limit).Allowed {
+		t.Error("refunds filled the bucket past its burst")
+	}
+}
+
+func TestMemoryLimiterSweepsIdleBuckets(t *testing.T) {
+	l, clock := newTestLimiter()
+	limit := Per(60, time.Minute, 3)
+
+	allow(t, l, "1:chat", limit)
+	clock.Advance(bucketIdle / 2)
+	allow(t, l, "2:chat", limit)
+
+	clock.Advance(bucketIdle / 2)
+	allow(t, l, "3:chat", limit)
+	if _, ok := l.buckets["1:chat"]; ok {
+		t.Error("idle bucket not swept")
+	}
+	if _, ok := l.buckets["2:chat"]; !ok {
+		t.Error("recently used bucket swept")
+	}
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Class groups actions by what they cost us. Generation actions end up as
+// model calls in the chat and lesson services; reads only touch the
+// database.
+type Class string
+
+const (
+	ClassRead       Class = "read"
+	ClassGeneration Class = "generation"
+)
+
+// Limits configures a Policy. PerAction applies to each user and action
+// pair; PerUser applies to all of a user's actions of the class combined
+// and is where daily spend caps go.
+type Limits struct {
+	PerAction map[Class]Limit
+	PerUser   map[Class]Limit
+}
+
+type Policy struct {
+	limiter Limiter
+	limits  Limits
+}
+
+func NewPolicy(limiter Limiter, limits Limits) *Policy {
+	return &Policy{limiter: limiter, limits: limits}
+}
+
+// Check takes a token for userID's action and returns a ResourceExhausted
+// status carrying RetryInfo when either bucket is empty. A request only
+// costs a token when both buckets allow it, so a user at the daily cap is
+// not also throttled per action. A failing limiter backend lets the request
+// through rather than taking the gateway down with it.
+func (p *Policy) Check(ctx context.Context, userID uint, action string, class Class) error {
+	actionKey := fmt.Sprintf("%d:%s", userID, action)
+	actionLimit, perAction := p.limits.PerAction[class]
+	if perAction {
+		if err := p.take(ctx, actionKey, actionLimit, action); err != nil {
+			return err
+		}
+	}
+	if limit, ok := p.limits.PerUser[class]; ok {
+		if err := p.take(ctx, fmt.Sprintf("%d:%s", userID, class), limit, string(class)+" requests"); err != nil {
+			if perAction {
+				p.refund(ctx, actionKey, actionLimit)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Policy) refund(ctx context.Context, key string, limit Limit) {
+	if err := p.limiter.Refund(ctx, key, limit); err != nil {
+		slog.WarnContext(ctx, "Rate limiter unavailable, token not refunded", "key", key, "err", err)
+	}
+}
+
+func (p *Policy) take(ctx context.Context, key string, limit Limit, what string) error {
+	result, err := p.limiter.Allow(ctx, key, limit)
+	if err != nil {
+		slog.WarnContext(ctx, "Rate limiter unavailable, allowing request", "key", key, "err", err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+
+	st := status.Newf(codes.ResourceExhausted, "rate limit exceeded for %s, retry in %ds", what, RetryAfterSeconds(result.RetryAfter))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// RetryAfter reads the RetryInfo detail of a gRPC error, if any.
+func RetryAfter(err error) time.Duration {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	return 0
+}
+
+// RetryAfterSeconds rounds d up to whole seconds, as in a Retry-After header.
+func RetryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicyRejectsWithRetryInfo(t *testing.T) {
+	l, _ := newTestLimiter()
+	p := NewPolicy(l, Limits{PerAction: map[Class]Limit{ClassRead: Per(60, time.Minute, 1)}})
+	ctx := context.Background()
+
+	if err := p.Check(ctx, 1, "get_chats", ClassRead); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	err := p.Check(ctx, 1, "get_chats", ClassRead)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v, want ResourceExhausted", err)
+	}
+	if got := RetryAfter(err); got != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", got)
+	}
+	if err := p.Check(ctx, 1, "search_messages", ClassRead); err != nil {
+		t.Errorf("another action: %v", err)
+	}
+}
+
+func TestPolicyCapDoesNotDrainActionBucket(t *testing.T) {
+	l, clock := newTestLimiter()
+	// The per-user bucket stands in for a daily cap; it refills well within
+	// bucketIdle so neither bucket is swept and recreated full.
+	p := NewPolicy(l, Limits{
+		PerAction: map[Class]Limit{ClassGeneration: Per(1, time.Hour, 1)},
+		PerUser:   map[Class]Limit{ClassGeneration: Per(1, 5*time.Minute, 1)},
+	})
+	ctx := context.Background()
+
+	if err := p.Check(ctx, 1, "start_message_stream", ClassGeneration); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	err := p.Check(ctx, 1, "generate_topic_plan", ClassGeneration)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v, want the per-user cap", err)
+	}
+	if got := RetryAfter(err); got != 5*time.Minute {
+		t.Errorf("RetryAfter = %v, want the per-user bucket's 5m", got)
+	}
+
+	// Once the per-user bucket refills, the action bucket must still hold
+	// the token the capped request did not use.
+	clock.Advance(5 * time.Minute)
+	if err := p.Check(ctx, 1, "generate_topic_plan", ClassGeneration); err != nil {
+		t.Errorf("after the per-user refill: %v", err)
+	}
+}
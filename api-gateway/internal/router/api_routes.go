@@ -0,0 +1,65 @@
+package router
+
+import (
+	"api-gateway/internal/handler"
+	"api-gateway/internal/ratelimit"
+	"api-gateway/pkg/proto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiRoute describes one REST endpoint. The same table registers the gin
+// route and produces its OpenAPI operation, so the two cannot drift apart.
+type apiRoute struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Query    []string
+	Request  interface{}
+	Response interface{}
+	Public   bool
+	Stream   bool
+	Class    ratelimit.Class
+	Handler  gin.HandlerFunc
+}
+
+func apiRoutes(chatHandler *handler.ChatHandler, userHandler *handler.UserHandler, lessonHandler *handler.LessonHandler) []apiRoute {
+	return []apiRoute{
+		{Method: "POST", Path: "/api-v1/login", Summary: "Exchange credentials for an access and refresh token", Tag: "auth", Public: true, Handler: userHandler.Login},
+		{Method: "POST", Path: "/api-v1/create_user", Summary: "Register a new account", Tag: "auth", Public: true, Handler: userHandler.CreateUser},
+		{Method: "POST", Path: "/api-v1/refresh_token", Summary: "Issue a new access token from a refresh token", Tag: "auth", Public: true, Handler: userHandler.RefreshToken},
+
+		{Method: "GET", Path: "/api-v1/chats", Summary: "List the caller's chats, pinned first; archived chats only with include_archived", Tag: "chats", Query: []string{"include_archived"}, Response: &proto.GetChatSummariesUIDResponse{}, Handler: chatHandler.ListChats},
+		{Method: "POST", Path: "/api-v1/chats", Summary: "Create a chat", Tag: "chats", Request: &proto.CreateChatRequest{}, Response: &proto.CreateChatResponse{}, Handler: chatHandler.CreateChat},
+		{Method: "DELETE", Path: "/api-v1/chats", Summary: "Delete all of the caller's chats", Tag: "chats", Response: &proto.DeleteAllChatsByUIDResponse{}, Handler: chatHandler.DeleteAllChats},
+		{Method: "DELETE", Path: "/api-v1/chats/:id", Summary: "Delete a chat", Tag: "chats", Response: &proto.DeleteChatByIDResponse{}, Handler: chatHandler.DeleteChat},
+		{Method: "PUT", Path: "/api-v1/chats/:id/name", Summary: "Rename a chat", Tag: "chats", Request: &proto.RenameChatRequest{}, Response: &proto.RenameChatResponse{}, Handler: chatHandler.SetChatName},
+		{Method: "PUT", Path: "/api-v1/chats/:id/pin", Summary: "Pin or unpin a chat", Tag: "chats", Request: &proto.PinChatRequest{}, Response: &proto.PinChatResponse{}, Handler: chatHandler.SetChatPinned},
+		{Method: "PUT", Path: "/api-v1/chats/:id/archive", Summary: "Archive or restore a chat", Tag: "chats", Request: &proto.ArchiveChatRequest{}, Response: &proto.ArchiveChatResponse{}, Handler: chatHandler.SetChatArchived},
+		{Method: "GET", Path: "/api-v1/chats/:id/messages", Summary: "Page through a chat's messages, newest first, at most 100 per page", Tag: "chats", Query: []string{"last_message_id", "limit"}, Response: &proto.GetRecentMessagesResponse{}, Handler: chatHandler.ListMessages},
+		{Method: "POST", Path: "/api-v1/chats/:id/messages", Summary: "Post a message and wait for the full answer", Tag: "chats", Request: &proto.CreateMessageRequest{}, Response: &proto.CreateMessageResponse{}, Class: ratelimit.ClassGeneration, Handler: chatHandler.CreateMessage},
+		{Method: "POST", Path: "/api-v1/chats/:id/messages:stream", Summary: "Post a message and stream the answer as Server-Sent Events (fragment, chat_renamed, message_complete, error)", Tag: "chats", Request: &proto.CreateMessageRequest{}, Stream: true, Class: ratelimit.ClassGeneration, Handler: chatHandler.StreamMessagesSSE},
+		{Method: "GET", Path: "/api-v1/messages/search", Summary: "Search the caller's messages, best matches first", Tag: "chats", Query: []string{"query", "limit", "offset"}, Response: &proto.SearchMessagesResponse{}, Handler: chatHandler.FindMessages},
+		{Method: "GET", Path: "/api-v1/messages/:id", Summary: "Fetch a single message", Tag: "chats", Response: &proto.GetMessageByIDResponse{}, Handler: chatHandler.GetMessage},
+
+		{Method: "POST", Path: "/api-v1/topic-plans/overview", Summary: "Generate a quick overview before committing to a topic plan", Tag: "lessons", Request: &proto.GenerateQuickResponseRequest{}, Response: &proto.GenerateQuickResponseResponse{}, Class: ratelimit.ClassGeneration, Handler: lessonHandler.GenerateTopicPlanOverview},
+		{Method: "POST", Path: "/api-v1/topic-plans", Summary: "Generate a topic plan", Tag: "lessons", Request: &proto.GenerateTopicPlanRequest{}, Response: &proto.GenerateTopicPlanResponse{}, Class: ratelimit.ClassGeneration, Handler: lessonHandler.GenerateTopicPlan},
+		{Method: "GET", Path: "/api-v1/topic-plans", Summary: "List the caller's topic plans", Tag: "lessons", Response: &proto.GetAllTopicPlansByUIDResponse{}, Handler: lessonHandler.ListTopicPlans},
+		{Method: "GET", Path: "/api-v1/topic-plans/:id", Summary: "Fetch a topic plan", Tag: "lessons", Response: &proto.GetTopicPlanByIDResponse{}, Handler: lessonHandler.GetTopicPlan},
+		{Method: "GET", Path: "/api-v1/topic-plans/:id/lessons", Summary: "List the lessons of a topic plan", Tag: "lessons", Response: &proto.GetAllLessonPlansByTopicIDResponse{}, Handler: lessonHandler.ListLessons},
+		{Method: "POST", Path: "/api-v1/topic-plans/:id/lessons", Summary: "Generate the lessons of a topic plan", Tag: "lessons", Response: &proto.GenerateLessonsResponse{}, Class: ratelimit.ClassGeneration, Handler: lessonHandler.GenerateLessons},
+		{Method: "GET", Path: "/api-v1/lessons/:id", Summary: "Fetch a lesson", Tag: "lessons", Response: &proto.GetLessonByIDResponse{}, Handler: lessonHandler.GetLesson},
+		{Method: "GET", Path: "/api-v1/lessons/:id/tests", Summary: "List the tests of a lesson", Tag: "lessons", Response: &proto.GetAllTestsByLessonIDResponse{}, Handler: lessonHandler.ListTests},
+		{Method: "POST", Path: "/api-v1/lessons/:id/tests", Summary: "Generate a test for a lesson", Tag: "lessons", Request: &proto.GenerateTestRequest{}, Response: &proto.GenerateTestResponse{}, Class: ratelimit.ClassGeneration, Handler: lessonHandler.GenerateTest},
+		{Method: "GET", Path: "/api-v1/tests/:id/questions", Summary: "List the questions of a test", Tag: "lessons", Response: &proto.GetAllQuestionsByTestIDResponse{}, Handler: lessonHandler.ListQuestions},
+		{Method: "POST", Path: "/api-v1/tests/:id/grade", Summary: "Grade a set of answers", Tag: "lessons", Request: &proto.GradeTestRequest{}, Response: &proto.GradeTestResponse{}, Class: ratelimit.ClassGeneration, Handler: lessonHandler.GradeTest},
+
+		{Method: "GET", Path: "/api-v1/users/search", Summary: "Search users by username", Tag: "users", Query: []string{"username"}, Response: &proto.SearchForUsersResponse{}, Handler: userHandler.SearchUsers},
+		{Method: "GET", Path: "/api-v1/users/me", Summary: "Fetch the caller's profile", Tag: "users", Response: &proto.GetUserInfoResponse{}, Handler: userHandler.GetCurrentUser},
+		{Method: "PUT", Path: "/api-v1/users/me", Summary: "Update the caller's profile", Tag: "users", Request: &proto.UpdateUserInfoRequest{}, Response: &proto.UpdateUserInfoResponse{}, Handler: userHandler.UpdateCurrentUser},
+		{Method: "DELETE", Path: "/api-v1/users/me", Summary: "Delete the caller's account", Tag: "users", Request: &proto.DeleteUserRequest{}, Response: &proto.DeleteUserResponse{}, Handler: userHandler.DeleteCurrentUser},
+		{Method: "PUT", Path: "/api-v1/users/me/password", Summary: "Change the caller's password", Tag: "users", Request: &proto.UpdateUserPasswordRequest{}, Response: &proto.UpdateUserPasswordResponse{}, Handler: userHandler.UpdateCurrentUserPassword},
+		{Method: "POST", Path: "/api-v1/users/me/verify-password", Summary: "Check the caller's password", Tag: "users", Request: &proto.VerifyUserPasswordRequest{}, Response: &proto.VerifyUserPasswordResponse{}, Handler: userHandler.VerifyCurrentUserPassword},
+	}
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"api-gateway/internal/middleware"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var timestampType = reflect.TypeOf(timestamppb.Timestamp{})
+
+// openAPIDocument builds an OpenAPI 3 description of routes. Schemas are
+// derived from the JSON tags of the generated proto structs, which is what
+// gin serializes.
+func openAPIDocument(routes []apiRoute) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	errorRef := schemaRef(reflect.TypeOf(middleware.WSError{}), schemas)
+
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		path, params := openAPIPath(route.Path)
+		for _, name := range route.Query {
+			params = append(params, map[string]interface{}{
+				"name":   name,
+				"in":     "query",
+				"schema": map[string]interface{}{"type": "string"},
+			})
+		}
+
+		operation := map[string]interface{}{
+			"summary": route.Summary,
+			"tags":    []string{route.Tag},
+			"responses": map[string]interface{}{
+				"200":     jsonContent("OK", route.Response, schemas),
+				"default": map[string]interface{}{"description": "Error", "content": map[string]interface{}{"application/json": map[string]interface{}{"schema": errorRef}}},
+			},
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if route.Request != nil {
+			operation["requestBody"] = jsonContent("", route.Request, schemas)
+		}
+		if route.Stream {
+			operation["responses"].(map[string]interface{})["200"] = map[string]interface{}{
+				"description": "Event stream",
+				"content":     map[string]interface{}{"text/event-stream": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+			}
+		}
+		if route.Public {
+			operation["security"] = []interface{}{}
+		}
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "API Gateway",
+			"version": "v1",
+		},
+		"paths":    paths,
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []string{}}},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+// openAPIPath rewrites gin's ":name" segments to "{name}" and returns the
+// matching path parameters.
+func openAPIPath(path string) (string, []interface{}) {
+	var params []interface{}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			name := segment[1:]
+			segments[i] = "{" + name + "}"
+			params = append(params, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "integer", "format": "int32"},
+			})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func jsonContent(description string, value interface{}, schemas map[string]interface{}) map[string]interface{} {
+	content := map[string]interface{}{}
+	if description != "" {
+		content["description"] = description
+	}
+	if value != nil {
+		content["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schemaRef(reflect.TypeOf(value), schemas)},
+		}
+	}
+	return content
+}
+
+func schemaRef(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timestampType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			// Reserve the name first so self-referencing messages terminate.
+			schemas[t.Name()] = nil
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaRef(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaRef(t.Elem(), schemas)}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int32, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = schemaRef(field.Type, schemas)
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
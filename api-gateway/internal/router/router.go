@@ -3,31 +3,110 @@ package router
 import (
 	"api-gateway/internal/config"
 	"api-gateway/internal/handler"
+	"api-gateway/internal/health"
 	"api-gateway/internal/middleware"
+	"api-gateway/internal/ratelimit"
 	"api-gateway/internal/services"
 	"api-gateway/internal/websocket"
 	"api-gateway/pkg/proto"
+	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func LoadRouter(cfg *config.Config, chatClient proto.ChatServiceClient, userClient proto.UserServiceClient, lessonClient proto.LessonServiceClient) *gin.Engine {
-	router := gin.Default()
+func LoadRouter(cfg *config.Config, chatClient proto.ChatServiceClient, userClient proto.UserServiceClient, lessonClient proto.LessonServiceClient, userHTTPClient *http.Client, prober *health.Prober) (*gin.Engine, *websocket.WebSocketManager) {
+	router := gin.New()
+	router.Use(gin.Recovery(), middleware.RequestLogger())
 
-	serviceFactory := services.NewDefaultServiceFactory(chatClient, userClient, lessonClient)
+	registry := websocket.NewRegistry()
 
-	wsManager := websocket.NewWebSocketManager(cfg, serviceFactory)
+	chatHandler := handler.NewChatHandler(cfg, chatClient, registry)
+	userHandler := handler.NewUserHandler(cfg, userClient, userHTTPClient)
+	lessonHandler := handler.NewLessonHandler(cfg, lessonClient, registry)
 
-	userHandler := handler.NewUserHandler(cfg, userClient)
+	serviceFactory := services.NewDefaultServiceFactory(chatHandler, userHandler, lessonHandler)
+
+	limits := ratelimit.NewPolicy(ratelimit.NewMemoryLimiter(), rateLimits(cfg))
+
+	wsManager := websocket.NewWebSocketManager(cfg, serviceFactory, registry, limits)
 
 	router.GET("/wss", func(ctx *gin.Context) {
 		wsManager.HandleConnections(ctx.Writer, ctx.Request)
 	})
 
+	router.GET("/healthz", prober.Liveness)
+	router.GET("/readyz", prober.Readiness)
+
+	router.Use(otelgin.Middleware("api-gateway"))
 	router.Use(middleware.JWTAuthMiddleware(cfg))
-	router.POST("/api-v1/login", userHandler.Login)
-	router.POST("/api-v1/create_user", userHandler.CreateUser)
-	router.POST("/api-v1/refresh_token", userHandler.RefreshToken)
 
-	return router
+	routes := apiRoutes(chatHandler, userHandler, lessonHandler)
+	registerRoutes(router, routes, limits)
+
+	openAPI := openAPIDocument(routes)
+	router.GET("/api-v1/openapi.json", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, openAPI)
+	})
+
+	return router, wsManager
+}
+
+// registerRoutes adds routes to the router. gin cannot match a literal ':'
+// inside a segment, so custom methods such as "messages:stream" share one
+// wildcard route per parent path and are matched by their full segment.
+func registerRoutes(router gin.IRoutes, routes []apiRoute, limits *ratelimit.Policy) {
+	customMethods := map[string]map[string]apiRoute{}
+	for _, route := range routes {
+		parent, last := path.Split(route.Path)
+		if !strings.Contains(last, ":") || strings.HasPrefix(last, ":") {
+			router.Handle(route.Method, route.Path, route.rateLimit(limits), route.Handler)
+			continue
+		}
+		key := route.Method + " " + parent
+		if customMethods[key] == nil {
+			customMethods[key] = map[string]apiRoute{}
+		}
+		customMethods[key][last] = route
+	}
+
+	for key, bySegment := range customMethods {
+		method, parent, _ := strings.Cut(key, " ")
+		bySegment := bySegment
+		router.Handle(method, parent+":customMethod", func(ctx *gin.Context) {
+			route, ok := bySegment[ctx.Param("customMethod")]
+			if !ok {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+			ctx.Set("route", route.Path)
+			if route.rateLimit(limits)(ctx); ctx.IsAborted() {
+				return
+			}
+			route.Handler(ctx)
+		})
+	}
+}
+
+func (route apiRoute) rateLimit(limits *ratelimit.Policy) gin.HandlerFunc {
+	class := route.Class
+	if class == "" {
+		class = ratelimit.ClassRead
+	}
+	return middleware.RateLimitMiddleware(limits, route.Method+" "+route.Path, class)
+}
+
+func rateLimits(cfg *config.Config) ratelimit.Limits {
+	return ratelimit.Limits{
+		PerAction: map[ratelimit.Class]ratelimit.Limit{
+			ratelimit.ClassRead:       ratelimit.Per(cfg.ReadPerMinute, time.Minute, cfg.ReadBurst),
+			ratelimit.ClassGeneration: ratelimit.Per(cfg.GenerationPerMinute, time.Minute, cfg.GenerationBurst),
+		},
+		PerUser: map[ratelimit.Class]ratelimit.Limit{
+			ratelimit.ClassGeneration: ratelimit.Per(cfg.GenerationPerDay, 24*time.Hour, cfg.GenerationPerDay),
+		},
+	}
 }
@@ -2,7 +2,6 @@ package services
 
 import (
 	"api-gateway/internal/handler"
-	"api-gateway/pkg/proto"
 )
 
 type ServiceFactory interface {
@@ -15,11 +14,11 @@ type DefaultServiceFactory struct {
 	lessonHandler *handler.LessonHandler
 }
 
-func NewDefaultServiceFactory(chatClient proto.ChatServiceClient, userClient proto.UserServiceClient, lessonClient proto.LessonServiceClient) *DefaultServiceFactory {
+func NewDefaultServiceFactory(chatHandler *handler.ChatHandler, userHandler *handler.UserHandler, lessonHandler *handler.LessonHandler) *DefaultServiceFactory {
 	return &DefaultServiceFactory{
-		chatHandler:   &handler.ChatHandler{ChatClient: chatClient},
-		userHandler:   &handler.UserHandler{UserClient: userClient},
-		lessonHandler: &handler.LessonHandler{LessonClient: lessonClient},
+		chatHandler:   chatHandler,
+		userHandler:   userHandler,
+		lessonHandler: lessonHandler,
 	}
 }
 
@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"api-gateway/internal/middleware"
+	"sync"
+)
+
+// Registry tracks the live sessions of each user so the gateway can push
+// server-initiated events to every device a user has connected.
+type Registry struct {
+	mu    sync.RWMutex
+	users map[uint]map[*Session]struct{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		users: make(map[uint]map[*Session]struct{}),
+	}
+}
+
+func (r *Registry) Register(session *Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions, ok := r.users[session.UserID()]
+	if !ok {
+		sessions = make(map[*Session]struct{})
+		r.users[session.UserID()] = sessions
+	}
+	sessions[session] = struct{}{}
+}
+
+func (r *Registry) Unregister(session *Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions, ok := r.users[session.UserID()]
+	if !ok {
+		return
+	}
+	delete(sessions, session)
+	if len(sessions) == 0 {
+		delete(r.users, session.UserID())
+	}
+}
+
+// Sessions returns a snapshot of the user's live sessions.
+func (r *Registry) Sessions(userID uint) []*Session {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]*Session, 0, len(r.users[userID]))
+	for session := range r.users[userID] {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// All returns a snapshot of every live session.
+func (r *Registry) All() []*Session {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []*Session
+	for _, userSessions := range r.users {
+		for session := range userSessions {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+func (r *Registry) ConnectionCount(userID uint) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.users[userID])
+}
+
+// SendToUser fans an event out to every live session of the user and returns
+// how many sessions it was queued on.
+func (r *Registry) SendToUser(userID uint, action string, data interface{}) int {
+	return r.SendToUserExcept(userID, nil, action, data)
+}
+
+// SendToUserExcept is SendToUser but skips the session except, typically the
+// one that made the request; nil skips none.
+func (r *Registry) SendToUserExcept(userID uint, except middleware.WSConn, action string, data interface{}) int {
+	sent := 0
+	for _, session := range r.Sessions(userID) {
+		if except != nil && middleware.WSConn(session) == except {
+			continue
+		}
+		middleware.SendWebSocketMessage(session, action, "", data)
+		sent++
+	}
+	return sent
+}
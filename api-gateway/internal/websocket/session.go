@@ -0,0 +1,232 @@
+package websocket
+
+import (
+	"api-gateway/internal/middleware"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a frame to the client.
+	writeWait = 10 * time.Second
+
+	// Time allowed to read the next pong from the client.
+	pongWait = 60 * time.Second
+
+	// Pings are sent at this interval, which must be shorter than pongWait.
+	pingPeriod = (pongWait * 9) / 10
+
+	// Largest inbound frame accepted from the client.
+	maxMessageSize = 64 * 1024
+
+	// Outbound frames buffered per connection before Send starts blocking.
+	sendQueueSize = 256
+
+	// How long before the access token expires the client is told to reauth.
+	tokenExpiryWarning = time.Minute
+)
+
+var (
+	ErrSessionClosed = errors.New("websocket session closed")
+	ErrSlowConsumer  = errors.New("websocket client is not reading fast enough")
+)
+
+// Session owns a single client connection. gorilla/websocket allows only one
+// concurrent writer, so every outbound frame goes through the send queue and
+// is written by the session's writePump goroutine.
+type Session struct {
+	conn      *websocket.Conn
+	userID    uint
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	authMu      sync.Mutex
+	token       string
+	expiresAt   time.Time
+	expiryTimer *time.Timer
+}
+
+func newSession(conn *websocket.Conn, userID uint, token string, expiresAt time.Time) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Session{
+		conn:   conn,
+		userID: userID,
+		send:   make(chan []byte, sendQueueSize),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	s.SetToken(token, expiresAt)
+	return s
+}
+
+// Send queues a text frame for the client. If the queue stays full for longer
+// than writeWait the client is considered too slow and is disconnected.
+func (s *Session) Send(msg []byte) error {
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
+
+	select {
+	case s.send <- msg:
+		return nil
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
+
+	timer := time.NewTimer(writeWait)
+	defer timer.Stop()
+
+	select {
+	case s.send <- msg:
+		return nil
+	case <-s.done:
+		return ErrSessionClosed
+	case <-timer.C:
+		slog.Info("Send queue full, disconnecting slow client")
+		s.Close()
+		return ErrSlowConsumer
+	}
+}
+
+// UserID is the user the socket was authenticated as during the upgrade.
+func (s *Session) UserID() uint {
+	return s.userID
+}
+
+// Token returns the access token currently bound to the session and when it
+// expires.
+func (s *Session) Token() (string, time.Time) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	return s.token, s.expiresAt
+}
+
+// SetToken binds a refreshed access token to the session and schedules a
+// token_expiring notice shortly before it runs out.
+func (s *Session) SetToken(token string, expiresAt time.Time) {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	s.token = token
+	s.expiresAt = expiresAt
+
+	if s.expiryTimer != nil {
+		s.expiryTimer.Stop()
+		s.expiryTimer = nil
+	}
+	if expiresAt.IsZero() {
+		return
+	}
+
+	warnIn := time.Until(expiresAt) - tokenExpiryWarning
+	if warnIn < 0 {
+		warnIn = 0
+	}
+	s.expiryTimer = time.AfterFunc(warnIn, func() {
+		middleware.SendWebSocketMessage(s, "token_expiring", "", map[string]int64{"expires_at": expiresAt.Unix()})
+	})
+}
+
+// Context is cancelled when the session closes. Only chat streams watch
+// it, to detach and wait for the client to resume them; other requests run
+// on contexts of their own and finish even after the client has gone.
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Done is closed once the session has been shut down.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close shuts the session down. The underlying connection is closed by
+// writePump once it has sent a close frame.
+func (s *Session) Close() {
+	s.closeWith(websocket.CloseNormalClosure)
+}
+
+// CloseGoingAway shuts the session down with a going-away close frame, which
+// tells the client to reconnect, typically to another gateway replica.
+func (s *Session) CloseGoingAway() {
+	s.closeWith(websocket.CloseGoingAway)
+}
+
+func (s *Session) closeWith(code int) {
+	s.closeOnce.Do(func() {
+		s.closeCode = code
+		close(s.done)
+		s.cancel()
+
+		s.authMu.Lock()
+		if s.expiryTimer != nil {
+			s.expiryTimer.Stop()
+		}
+		s.authMu.Unlock()
+	})
+}
+
+// readPump delivers inbound frames to onMessage until the connection fails or
+// the client stops answering pings.
+func (s *Session) readPump(onMessage func(msg []byte)) {
+	defer s.Close()
+
+	s.conn.SetReadLimit(maxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Warn("Socket read failed", "user_id", s.userID, "err", err)
+			}
+			return
+		}
+		onMessage(msg)
+	}
+}
+
+// writePump is the only goroutine that writes to the connection.
+func (s *Session) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		s.Close()
+		s.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := s.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				slog.Error("Error sending WebSocket message", "err", err)
+				return
+			}
+		case <-ticker.C:
+			s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				slog.Error("Ping failed", "err", err)
+				return
+			}
+		case <-s.done:
+			s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(s.closeCode, ""), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
@@ -2,79 +2,266 @@ package websocket
 
 import (
 	"api-gateway/internal/config"
+	"api-gateway/internal/logging"
+	"api-gateway/internal/metrics"
 	"api-gateway/internal/middleware"
+	"api-gateway/internal/ratelimit"
 	"api-gateway/internal/services"
+	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// tracer starts the span that every backend call made for a socket message
+// descends from.
+var tracer = otel.Tracer("api-gateway/websocket")
+
 type WebSocketManager struct {
 	upgrader       websocket.Upgrader
 	serviceFactory services.ServiceFactory
+	registry       *Registry
+	limits         *ratelimit.Policy
 	config         *config.Config
+
+	// inflight counts the messages being handled, including chat streams
+	// and lesson generations, so Shutdown can wait for them. Once draining
+	// is set no new work is taken on.
+	drainMu  sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// generationActions are the socket actions that end in a model call and are
+// limited as ratelimit.ClassGeneration.
+var generationActions = map[string]bool{
+	"start_message_stream":         true,
+	"generate_topic_plan_overview": true,
+	"generate_topic_plan":          true,
+	"generate_lessons":             true,
+	"generate_test":                true,
+	"grade_test":                   true,
 }
 
-func NewWebSocketManager(cfg *config.Config, serviceFactory services.ServiceFactory) *WebSocketManager {
+// drainingActions are still accepted while the gateway drains because they
+// act on work that is already in flight.
+var drainingActions = map[string]bool{
+	"reauth":                true,
+	"cancel_message_stream": true,
+	"resume_message_stream": true,
+}
+
+func NewWebSocketManager(cfg *config.Config, serviceFactory services.ServiceFactory, registry *Registry, limits *ratelimit.Policy) *WebSocketManager {
 	return &WebSocketManager{
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
 		serviceFactory: serviceFactory,
+		registry:       registry,
+		limits:         limits,
 		config:         cfg,
 	}
 }
 
 func (manager *WebSocketManager) HandleConnections(w http.ResponseWriter, r *http.Request) {
-	tokenString := r.URL.Query().Get("token")
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.NewValidationError("Unexpected signing method", jwt.ValidationErrorSignatureInvalid)
-		}
-		return []byte(manager.config.AccessSecret), nil
-	})
+	if manager.isDraining() {
+		http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+		return
+	}
 
-	if err != nil || !token.Valid {
+	tokenString := r.URL.Query().Get("token")
+	userID, expiresAt, err := middleware.ParseAccessToken(tokenString, manager.config.AccessSecret)
+	if err != nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	conn, err := manager.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("Upgrade Failed: %v", err)
+		slog.Error("Upgrade Failed", "err", err)
 		return
 	}
-	defer conn.Close()
 
-	for {
-		_, msg, err := conn.ReadMessage()
-		if err != nil {
-			log.Println("Read Failed:", err)
-			break
-		}
+	session := newSession(conn, userID, tokenString, expiresAt)
+	manager.registry.Register(session)
+	defer manager.registry.Unregister(session)
+	metrics.ConnectionOpened()
+	defer metrics.ConnectionClosed()
 
-		go manager.handleMessage(conn, msg)
+	go session.writePump()
 
-	}
+	session.readPump(func(msg []byte) {
+		go manager.handleMessage(session, msg)
+	})
 }
 
-func (manager *WebSocketManager) handleMessage(conn *websocket.Conn, msg []byte) {
+func (manager *WebSocketManager) handleMessage(conn *Session, msg []byte) {
 	var wsMsg middleware.WSMessage
 	if err := json.Unmarshal(msg, &wsMsg); err != nil {
-		log.Println("Unmarshal failed:", err)
+		slog.Warn("Malformed socket message", "user_id", conn.UserID(), "err", err)
+		middleware.SendWebSocketError(conn, wsMsg.Action, wsMsg.RequestID, status.Errorf(codes.InvalidArgument, "malformed message: %v", err))
 		return
 	}
 
-	log.Printf("Received message with type: %s", wsMsg.Type)
+	ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+	ctx, span := tracer.Start(ctx, "ws "+wsMsg.Type+"."+wsMsg.Action,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("ws.type", wsMsg.Type),
+			attribute.String("ws.action", wsMsg.Action),
+			attribute.String("ws.request_id", wsMsg.RequestID),
+			attribute.Int("enduser.id", int(conn.UserID())),
+		))
+	defer span.End()
+
+	slog.InfoContext(ctx, "Received socket message",
+		"type", wsMsg.Type,
+		"action", wsMsg.Action,
+		"ws_request_id", wsMsg.RequestID,
+		"user_id", conn.UserID())
+
+	if !drainingActions[wsMsg.Action] {
+		if !manager.track() {
+			metrics.ActionReceived(wsMsg.Type, wsMsg.Action, "rejected")
+			span.SetStatus(otelcodes.Error, "draining")
+			middleware.SendWebSocketError(conn, wsMsg.Action, wsMsg.RequestID, status.Error(codes.Unavailable, "gateway is shutting down, reconnect and retry"))
+			return
+		}
+		defer manager.inflight.Done()
+	}
+
+	if wsMsg.Action == "reauth" {
+		manager.reauthenticate(conn, wsMsg)
+		return
+	}
+
+	token, err := manager.authorizeMessage(conn, wsMsg.JWT)
+	if err != nil {
+		metrics.ActionReceived(wsMsg.Type, wsMsg.Action, "rejected")
+		span.SetStatus(otelcodes.Error, "rejected")
+		middleware.SendWebSocketError(conn, wsMsg.Action, wsMsg.RequestID, err)
+		return
+	}
+	wsMsg.JWT = token
+
+	class := ratelimit.ClassRead
+	if generationActions[wsMsg.Action] {
+		class = ratelimit.ClassGeneration
+	}
+	if err := manager.limits.Check(conn.Context(), conn.UserID(), wsMsg.Action, class); err != nil {
+		slog.InfoContext(ctx, "Rate limited socket action", "user_id", conn.UserID(), "action", wsMsg.Action)
+		metrics.ActionReceived(wsMsg.Type, wsMsg.Action, "rate_limited")
+		span.SetStatus(otelcodes.Error, "rate limited")
+		middleware.SendWebSocketError(conn, wsMsg.Action, wsMsg.RequestID, err)
+		return
+	}
 
 	handler := manager.serviceFactory.GetHandler(wsMsg.Type)
 	if handler != nil {
-		handler.ProcessMessage(conn, wsMsg)
+		metrics.ActionReceived(wsMsg.Type, wsMsg.Action, "dispatched")
+		handler.ProcessMessage(ctx, conn, wsMsg)
 	} else {
-		log.Println("Handler not found for message type:", wsMsg.Type)
+		metrics.ActionReceived(wsMsg.Type, wsMsg.Action, "rejected")
+		span.SetStatus(otelcodes.Error, "unknown message type")
+		slog.WarnContext(ctx, "Handler not found for message type", "type", wsMsg.Type)
+		middleware.SendWebSocketError(conn, wsMsg.Action, wsMsg.RequestID, status.Errorf(codes.Unimplemented, "unknown message type: %s", wsMsg.Type))
+	}
+}
+
+// authorizeMessage checks the token a message carries against the user the
+// socket is bound to. Messages without a token use the session's token.
+func (manager *WebSocketManager) authorizeMessage(conn *Session, token string) (string, error) {
+	if token == "" {
+		sessionToken, expiresAt := conn.Token()
+		if !expiresAt.IsZero() && time.Now().After(expiresAt) {
+			return "", status.Error(codes.Unauthenticated, "access token expired, send reauth with a refreshed token")
+		}
+		return sessionToken, nil
+	}
+
+	userID, _, err := middleware.ParseAccessToken(token, manager.config.AccessSecret)
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "invalid or expired token: %v", err)
+	}
+	if userID != conn.UserID() {
+		return "", status.Error(codes.PermissionDenied, "token does not belong to the user bound to this connection")
+	}
+	return token, nil
+}
+
+// reauthenticate swaps a refreshed access token into the session so a socket
+// can outlive the token it was opened with.
+func (manager *WebSocketManager) reauthenticate(conn *Session, msg middleware.WSMessage) {
+	userID, expiresAt, err := middleware.ParseAccessToken(msg.JWT, manager.config.AccessSecret)
+	if err != nil {
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, status.Errorf(codes.Unauthenticated, "invalid or expired token: %v", err))
+		return
+	}
+	if userID != conn.UserID() {
+		middleware.SendWebSocketError(conn, msg.Action, msg.RequestID, status.Error(codes.PermissionDenied, "token does not belong to the user bound to this connection"))
+		return
+	}
+
+	conn.SetToken(msg.JWT, expiresAt)
+	middleware.SendWebSocketMessage(conn, "reauth_resp", msg.RequestID, map[string]int64{"expires_at": expiresAt.Unix()})
+}
+
+// track registers a message as in flight. It fails once the manager is
+// draining, so Shutdown never waits on work that started after it.
+func (manager *WebSocketManager) track() bool {
+	manager.drainMu.Lock()
+	defer manager.drainMu.Unlock()
+	if manager.draining {
+		return false
+	}
+	manager.inflight.Add(1)
+	return true
+}
+
+func (manager *WebSocketManager) isDraining() bool {
+	manager.drainMu.Lock()
+	defer manager.drainMu.Unlock()
+	return manager.draining
+}
+
+// Shutdown stops taking on new socket work, waits until the messages in
+// flight are handled or ctx expires, and then closes every session with a
+// going-away frame. Streams still running at that point are cancelled by the
+// chat service once the gateway's connection to it goes away, which leaves
+// the message checkpointed as cancelled rather than half written.
+func (manager *WebSocketManager) Shutdown(ctx context.Context) error {
+	manager.drainMu.Lock()
+	manager.draining = true
+	manager.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		manager.inflight.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	sessions := manager.registry.All()
+	for _, session := range sessions {
+		session.CloseGoingAway()
 	}
+	slog.Info("Closed WebSocket sessions", "sessions", len(sessions))
+	return err
 }
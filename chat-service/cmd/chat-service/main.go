@@ -2,53 +2,171 @@ package main
 
 import (
 	"chat-service/pkg/config"
+	"chat-service/pkg/healthcheck"
 	"chat-service/pkg/interceptors"
-	"chat-service/pkg/model"
+	"chat-service/pkg/llm"
+	"chat-service/pkg/logging"
+	"chat-service/pkg/metrics"
+	"chat-service/pkg/mtls"
 	"chat-service/pkg/proto"
 	"chat-service/pkg/repository"
 	"chat-service/pkg/server"
 	"chat-service/pkg/service"
+	"chat-service/pkg/tracing"
+	"context"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	cfg := config.LoadConfig(os.Args[1:])
+	logging.Setup("chat-service", cfg.Log)
+
+	var tlsStore *mtls.Store
+	if cfg.TLS.Enabled() {
+		store, err := mtls.NewStore(cfg.TLS)
+		if err != nil {
+			log.Fatalf("Failed to load mTLS material: %v", err)
+		}
+		tlsStore = store
+	} else {
+		slog.Info("mTLS disabled, serving plaintext gRPC")
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "chat-service", cfg.TracingExporter)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
 
 	db, err := repository.NewDB(cfg)
 	if err != nil {
 		log.Fatalf("Failed to init db: %v", err)
 	}
-	lis, err := net.Listen("tcp", ":8080")
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to register db metrics: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to register db tracing: %v", err)
+	}
+	metrics.Serve(cfg.MetricsAddr)
+
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(
-		interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
-		grpc.StreamInterceptor(interceptors.NewStreamInterceptor(cfg.AccessSecret)),
-	)
+	serverOpts := []grpc.ServerOption{
+		grpc.WaitForHandlers(true),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logging.UnaryServerInterceptor(), interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), logging.StreamServerInterceptor(), interceptors.NewStreamInterceptor(cfg.AccessSecret)),
+	}
+	if tlsStore != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsStore.ServerConfig())))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	chatRepo := repository.NewChatRepository(db)
 	msgRepo := repository.NewMessageRepository(db)
 
-	if err := db.AutoMigrate(&model.Chat{}, &model.Message{}); err != nil {
-		log.Fatalf("failed to auto-migrate: %v", err)
+	if cfg.MigrateOnStart {
+		migrator, err := newMigrator(db)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
 	}
 
 	chatService := service.NewChatService(chatRepo)
 	msgService := service.NewMessageService(msgRepo, chatRepo)
-	openAIService := service.NewOpenAIService(cfg, msgService)
+	chatModel, err := llm.Resolve(cfg.LLM.Providers(), cfg.ChatModel)
+	if err != nil {
+		log.Fatalf("Failed to set up chat model: %v", err)
+	}
+	titleModel, err := llm.Resolve(cfg.LLM.Providers(), cfg.TitleModel)
+	if err != nil {
+		log.Fatalf("Failed to set up title model: %v", err)
+	}
+	summaryModel, err := llm.Resolve(cfg.LLM.Providers(), cfg.SummaryModel)
+	if err != nil {
+		log.Fatalf("Failed to set up summary model: %v", err)
+	}
+	contextBuilder := service.NewContextBuilder(summaryModel, cfg.ContextTokens, chatRepo, msgRepo)
+	llmService := service.NewLLMService(chatModel, titleModel, contextBuilder, msgService)
 
-	chatServer := server.NewChatServer(chatService, msgService, openAIService)
+	chatServer := server.NewChatServer(chatService, msgService, llmService)
 
 	proto.RegisterChatServiceServer(grpcServer, chatServer)
 
-	log.Println("gRPC server is listening on ", lis.Addr())
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthcheck.NewChecker(healthServer, []string{proto.ChatService_ServiceDesc.ServiceName}, map[string]healthcheck.Check{
+		"database": healthcheck.Database(db),
+		"llm":      healthcheck.LLM(cfg.LLM, cfg.ChatModel, cfg.TitleModel, cfg.SummaryModel),
+	}).Start()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		slog.Info("gRPC server is listening", "addr", lis.Addr().String())
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("Failed to serve: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down", "grace_period", cfg.ShutdownGracePeriod)
+
+	// NOT_SERVING steers the gateway's health-checked balancer to other
+	// replicas while the calls already running here finish.
+	healthServer.Shutdown()
+	stopGracefully(grpcServer, cfg.ShutdownGracePeriod)
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close database", "err", err)
+		}
 	}
+	slog.Info("Server stopped")
+}
+
+// stopGracefully lets running calls finish within grace and then cancels the
+// rest. The server waits for cancelled handlers to return, so a chat stream
+// cut off here still records its message as cancelled before the database
+// closes.
+func stopGracefully(grpcServer *grpc.Server, grace time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
 
+	select {
+	case <-stopped:
+	case <-time.After(grace):
+		slog.Warn("Grace period ended, cancelling remaining calls", "grace_period", grace)
+		grpcServer.Stop()
+		<-stopped
+	}
 }
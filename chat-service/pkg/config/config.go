@@ -1,37 +1,182 @@
 package config
 
 import (
+	"chat-service/pkg/llm"
+	"chat-service/pkg/logging"
+	"chat-service/pkg/mtls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"time"
 )
 
 type Config struct {
-	OpenAIKey    string
 	DBHost       string
 	DBUser       string
 	DBPassword   string
 	DBName       string
 	DBPort       string
 	AccessSecret string
+
+	// LLM configures the model providers.
+	LLM llm.Config
+
+	// ChatModel answers chat messages and TitleModel names chats after
+	// their first answer, as provider/model.
+	ChatModel  string
+	TitleModel string
+
+	// SummaryModel folds chat history that no longer fits ContextTokens,
+	// the chat model's prompt budget, into a running summary.
+	SummaryModel  string
+	ContextTokens int
+
+	// GRPCAddr is the address the gRPC server listens on.
+	GRPCAddr string
+
+	// TLS enables mTLS on the gRPC listener when configured.
+	TLS mtls.Config
+
+	Log logging.Config
+
+	MetricsAddr string
+
+	// TracingExporter is "otlp", "stdout" or "none".
+	TracingExporter string
+
+	// MigrateOnStart applies pending migrations before serving. Replicas
+	// take turns through the migration lock.
+	MigrateOnStart bool
+
+	// ShutdownGracePeriod is how long running calls get to finish after
+	// SIGTERM before they are cancelled.
+	ShutdownGracePeriod time.Duration
+
+	// PrintConfig is set by --print-config.
+	PrintConfig bool
+
+	layers *layers
 }
 
-func readSecretFile(secretName string) string {
-	secretPath := "/run/secrets/" + secretName
-	content, err := os.ReadFile(secretPath)
+// minContextTokens leaves room for the system prompt, a running summary and
+// a few turns.
+const minContextTokens = 2000
+
+var settings = []setting{
+	{Key: "ACCESS_SECRET", Secret: true, Usage: "key that verifies access tokens"},
+	{Key: "CHAT_DB_HOST", Default: "localhost", Usage: "database host"},
+	{Key: "CHAT_DB_PORT", Default: "5432", Usage: "database port"},
+	{Key: "CHAT_DB_USER", Usage: "database user"},
+	{Key: "CHAT_DB_PASSWORD", Secret: true, Usage: "database password"},
+	{Key: "CHAT_DB_NAME", Usage: "database name"},
+	{Key: "OPENAI_API_KEY", Secret: true, Usage: "OpenAI API key, needed by openai/ models"},
+	{Key: "OPENAI_BASE_URL", Usage: "overrides the OpenAI API endpoint"},
+	{Key: "LLM_LOCAL_BASE_URL", Usage: "OpenAI compatible endpoint for local/ models, such as http://ollama:11434/v1"},
+	{Key: "LLM_LOCAL_API_KEY", Secret: true, Usage: "API key for the local endpoint, if it needs one"},
+	{Key: "LLM_CHAT_MODEL", Default: "openai/gpt-4o-mini", Usage: "model that answers chat messages"},
+	{Key: "LLM_TITLE_MODEL", Default: "openai/gpt-4o-mini", Usage: "cheap model that titles chats after their first answer"},
+	{Key: "LLM_SUMMARY_MODEL", Default: "openai/gpt-4o-mini", Usage: "model that folds old chat turns into running summaries"},
+	{Key: "LLM_CONTEXT_TOKENS", Default: "8000", Usage: "prompt token budget of chat models not in LLM_CONTEXT_BUDGETS"},
+	{Key: "LLM_CONTEXT_BUDGETS", Usage: "comma separated provider/model=tokens prompt budgets, such as local/llama3.1:8b=3000"},
+	{Key: "GRPC_ADDR", Default: ":8080", Usage: "gRPC listen address"},
+	{Key: "TLS_CA_FILE", Usage: "CA bundle that signs peer certificates"},
+	{Key: "TLS_CERT_FILE", Usage: "service certificate, enables mTLS"},
+	{Key: "TLS_KEY_FILE", Usage: "service private key"},
+	{Key: "TLS_ALLOWED_PEERS", Usage: "comma separated SANs accepted from peers"},
+	{Key: "LOG_LEVEL", Default: "info", Usage: "debug, info, warn or error"},
+	{Key: "LOG_FORMAT", Default: "json", Usage: "json or text"},
+	{Key: "LOG_REDACT_CONTENT", Default: "true", Usage: "redact user and model content in logs"},
+	{Key: "METRICS_ADDR", Default: ":9090", Usage: "Prometheus metrics listen address"},
+	{Key: "TRACING_EXPORTER", Default: "none", Usage: "otlp, stdout or none"},
+	{Key: "MIGRATE_ON_START", Default: "true", Usage: "apply pending database migrations on startup"},
+	{Key: "SHUTDOWN_GRACE_PERIOD", Default: "30s", Usage: "time running calls get to finish on shutdown"},
+}
+
+// Load resolves the configuration from args and the process environment.
+// The returned error lists every invalid setting; the Config is still
+// returned with it so --print-config can show what was resolved.
+func Load(args []string) (*Config, error) {
+	l, err := resolve("chat-service", settings, args, os.LookupEnv)
 	if err != nil {
-		log.Fatalf("Failed to read secret file %s, error: %v", secretPath, err)
+		return nil, err
+	}
+
+	p := &parser{l: l}
+	cfg := &Config{
+		AccessSecret: p.Required("ACCESS_SECRET"),
+		DBHost:       p.Required("CHAT_DB_HOST"),
+		DBPort:       p.Required("CHAT_DB_PORT"),
+		DBUser:       p.Required("CHAT_DB_USER"),
+		DBPassword:   p.Required("CHAT_DB_PASSWORD"),
+		DBName:       p.Required("CHAT_DB_NAME"),
+		LLM: llm.Config{
+			OpenAIKey:     p.String("OPENAI_API_KEY"),
+			OpenAIBaseURL: p.String("OPENAI_BASE_URL"),
+			LocalBaseURL:  p.String("LLM_LOCAL_BASE_URL"),
+			LocalKey:      p.String("LLM_LOCAL_API_KEY"),
+		},
+		ChatModel:    p.Required("LLM_CHAT_MODEL"),
+		TitleModel:   p.Required("LLM_TITLE_MODEL"),
+		SummaryModel: p.Required("LLM_SUMMARY_MODEL"),
+		GRPCAddr:     p.Addr("GRPC_ADDR"),
+		TLS: mtls.Config{
+			CAFile:       p.String("TLS_CA_FILE"),
+			CertFile:     p.String("TLS_CERT_FILE"),
+			KeyFile:      p.String("TLS_KEY_FILE"),
+			AllowedPeers: p.List("TLS_ALLOWED_PEERS"),
+		},
+		Log: logging.Config{
+			Level:         p.OneOf("LOG_LEVEL", "debug", "info", "warn", "error"),
+			Format:        p.OneOf("LOG_FORMAT", "json", "text"),
+			RedactContent: p.Bool("LOG_REDACT_CONTENT"),
+		},
+		MetricsAddr:         p.Addr("METRICS_ADDR"),
+		TracingExporter:     p.OneOf("TRACING_EXPORTER", "otlp", "stdout", "none"),
+		MigrateOnStart:      p.Bool("MIGRATE_ON_START"),
+		ShutdownGracePeriod: p.Duration("SHUTDOWN_GRACE_PERIOD"),
+		PrintConfig:         l.printConfig,
+		layers:              l,
+	}
+	cfg.ContextTokens = p.Int("LLM_CONTEXT_TOKENS")
+	if budget, ok := p.IntMap("LLM_CONTEXT_BUDGETS")[cfg.ChatModel]; ok {
+		cfg.ContextTokens = budget
+	}
+	if cfg.ContextTokens < minContextTokens {
+		p.Check(fmt.Errorf("context budget of %s is %d tokens, want at least %d", cfg.ChatModel, cfg.ContextTokens, minContextTokens))
 	}
-	return string(content)
+	p.Check(cfg.TLS.Validate())
+	p.Check(cfg.LLM.Validate(cfg.ChatModel, cfg.TitleModel, cfg.SummaryModel))
+
+	return cfg, p.Err()
+}
+
+// Print writes the resolved settings and where each came from, with secrets
+// redacted.
+func (c *Config) Print(w io.Writer) {
+	c.layers.Print(w)
 }
 
-func LoadConfig() *Config {
-	return &Config{
-		AccessSecret: readSecretFile("ACCESS_SECRET"),
-		OpenAIKey:    readSecretFile("OPENAI_API_KEY"),
-		DBHost:       readSecretFile("CHAT_DB_HOST"),
-		DBUser:       readSecretFile("CHAT_DB_USER"),
-		DBPassword:   readSecretFile("CHAT_DB_PASSWORD"),
-		DBName:       readSecretFile("CHAT_DB_NAME"),
-		DBPort:       readSecretFile("CHAT_DB_PORT"),
+// LoadConfig loads the configuration from args, usually os.Args[1:], and the
+// environment and exits on invalid settings. With --print-config it prints
+// the resolved configuration and exits.
+func LoadConfig(args []string) *Config {
+	cfg, err := Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if cfg != nil && cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\ninvalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
+	return cfg
 }
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testSettings = []setting{
+	{Key: "PORT", Default: "8080", Usage: "listen port"},
+	{Key: "API_KEY", Secret: true, Usage: "API key"},
+}
+
+// fakeEnv returns a lookupEnv over vars.
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestResolveLayers(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		secrets    map[string]string
+		env        map[string]string
+		args       []string
+		want       string
+		wantSource string
+	}{
+		{
+			name:       "default",
+			want:       "8080",
+			wantSource: sourceDefault,
+		},
+		{
+			name:       "file overrides default",
+			file:       "port: 9090\n",
+			want:       "9090",
+			wantSource: sourceFile,
+		},
+		{
+			name:       "secret overrides file",
+			file:       "port: 9090\n",
+			secrets:    map[string]string{"PORT": "7070\n"},
+			want:       "7070",
+			wantSource: sourceSecret,
+		},
+		{
+			name:       "missing secret file leaves file value",
+			file:       "port: 9090\n",
+			secrets:    map[string]string{"OTHER": "1"},
+			want:       "9090",
+			wantSource: sourceFile,
+		},
+		{
+			name:       "env overrides secret",
+			file:       "port: 9090\n",
+			secrets:    map[string]string{"PORT": "7070"},
+			env:        map[string]string{"PORT": "6060"},
+			want:       "6060",
+			wantSource: sourceEnv,
+		},
+		{
+			name:       "empty env overrides file and secret",
+			file:       "port: 9090\n",
+			secrets:    map[string]string{"PORT": "7070"},
+			env:        map[string]string{"PORT": ""},
+			want:       "",
+			wantSource: sourceEnv,
+		},
+		{
+			name:       "flag overrides env",
+			env:        map[string]string{"PORT": "6060"},
+			args:       []string{"--port", "5050"},
+			want:       "5050",
+			wantSource: sourceFlag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			secretsDir := filepath.Join(dir, "secrets")
+			if err := os.Mkdir(secretsDir, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			for key, content := range tt.secrets {
+				if err := os.WriteFile(filepath.Join(secretsDir, key), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			env := map[string]string{"SECRETS_DIR": secretsDir}
+			for key, v := range tt.env {
+				env[key] = v
+			}
+			if tt.file != "" {
+				path := filepath.Join(dir, "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				env["CONFIG_FILE"] = path
+			}
+
+			l, err := resolve("test", testSettings, tt.args, fakeEnv(env))
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			got := l.values["PORT"]
+			if got.raw != tt.want || !strings.HasPrefix(got.source, tt.wantSource) {
+				t.Errorf("PORT = %q from %q, want %q from %s", got.raw, got.source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestResolveRejectsSecretFlagsAndUnknownFileKeys(t *testing.T) {
+	env := fakeEnv(map[string]string{"SECRETS_DIR": t.TempDir()})
+	if _, err := resolve("test", testSettings, []string{"--api-key", "k"}, env); err == nil {
+		t.Error("resolve accepted a secret as a flag")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("prot = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolve("test", testSettings, []string{"--config", path}, env); err == nil || !strings.Contains(err.Error(), "PROT") {
+		t.Errorf("err = %v, want one naming the unknown setting", err)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	l, err := resolve("test", testSettings, nil, fakeEnv(map[string]string{"SECRETS_DIR": t.TempDir()}))
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	p := &parser{l: l}
+	p.Required("PORT")
+	if err := p.Err(); err != nil {
+		t.Errorf("Required of a defaulted setting: %v", err)
+	}
+	p.Required("API_KEY")
+	if err := p.Err(); err == nil || !strings.Contains(err.Error(), "API_KEY: required") {
+		t.Errorf("err = %v, want API_KEY: required", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	env := fakeEnv(map[string]string{"SECRETS_DIR": t.TempDir(), "API_KEY": "sk-live-123"})
+	l, err := resolve("test", testSettings, []string{"--print-config"}, env)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !l.printConfig {
+		t.Error("printConfig not set by --print-config")
+	}
+
+	var out bytes.Buffer
+	l.Print(&out)
+	if strings.Contains(out.String(), "sk-live-123") {
+		t.Errorf("Print leaked the secret:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "[REDACTED]") || !strings.Contains(out.String(), "8080") {
+		t.Errorf("Print = \n%s\nwant the port and a redacted key", out.String())
+	}
+}
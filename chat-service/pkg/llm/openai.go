@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"chat-service/pkg/metrics"
+	"chat-service/pkg/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// openAIProvider talks to the OpenAI API or a server compatible with it.
+// Every call is recorded in the openai_* metrics and traced as a child span.
+type openAIProvider struct {
+	client *openai.Client
+	system string
+}
+
+// NewOpenAI returns the hosted OpenAI provider. baseURL overrides the API
+// endpoint, for proxies, and may be empty.
+func NewOpenAI(apiKey, baseURL string) Provider {
+	cfg := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		cfg.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	return &openAIProvider{client: openai.NewClientWithConfig(cfg), system: "openai"}
+}
+
+// NewCompatible returns a provider for a self-hosted server that speaks the
+// OpenAI API, such as Ollama (http://ollama:11434/v1) or vLLM. Most of them
+// ignore the API key.
+func NewCompatible(baseURL, apiKey string) Provider {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.BaseURL = strings.TrimSuffix(baseURL, "/")
+	return &openAIProvider{client: openai.NewClientWithConfig(cfg), system: "openai_compatible"}
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	resp, err := p.createChatCompletion(ctx, chatRequest(req))
+	if err != nil {
+		return Response{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return Response{}, errors.New("llm: response has no choices")
+	}
+	return Response{Content: resp.Choices[0].Message.Content, Usage: usage(resp.Usage)}, nil
+}
+
+// CompleteStructured forces a call to fn as a tool. Servers that ignore the
+// forced tool choice tend to answer with the JSON as plain content, so that
+// is accepted too.
+func (p *openAIProvider) CompleteStructured(ctx context.Context, req Request, fn Function) (string, Usage, error) {
+	chatReq := chatRequest(req)
+	chatReq.Tools = []openai.Tool{{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        fn.Name,
+			Description: fn.Description,
+			Parameters:  fn.Parameters,
+		},
+	}}
+	chatReq.ToolChoice = openai.ToolChoice{
+		Type:     openai.ToolTypeFunction,
+		Function: openai.ToolFunction{Name: fn.Name},
+	}
+
+	resp, err := p.createChatCompletion(ctx, chatReq)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return "", Usage{}, errors.New("llm: response has no choices")
+	}
+
+	message := resp.Choices[0].Message
+	for _, call := range message.ToolCalls {
+		if call.Function.Name == fn.Name && call.Function.Arguments != "" {
+			return call.Function.Arguments, usage(resp.Usage), nil
+		}
+	}
+	if content := stripCodeFence(message.Content); strings.HasPrefix(content, "{") {
+		return content, usage(resp.Usage), nil
+	}
+	return "", Usage{}, fmt.Errorf("llm: model did not call %s", fn.Name)
+}
+
+func (p *openAIProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	chatReq := chatRequest(req)
+	chatReq.Stream = true
+	chatReq.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	llmCtx, span := tracing.StartLLMSpan(ctx, p.system, "chat.completions", chatReq.Model)
+	start := time.Now()
+	stream, err := p.client.CreateChatCompletionStream(llmCtx, chatReq)
+	if err != nil {
+		metrics.ObserveOpenAI(chatReq.Model, start, 0, 0, err)
+		tracing.EndLLMSpan(span, 0, 0, err)
+		return nil, err
+	}
+	return &openAIStream{stream: stream, model: chatReq.Model, span: span, start: start}, nil
+}
+
+func (p *openAIProvider) createChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	ctx, span := tracing.StartLLMSpan(ctx, p.system, "chat.completions", req.Model)
+	start := time.Now()
+	resp, err := p.client.CreateChatCompletion(ctx, req)
+	metrics.ObserveOpenAI(req.Model, start, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, err)
+	tracing.EndLLMSpan(span, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, err)
+	return resp, err
+}
+
+// openAIStream records the request once, when the stream is closed, with
+// the usage from its last chunk and the first error other than io.EOF.
+type openAIStream struct {
+	stream *openai.ChatCompletionStream
+	model  string
+	span   trace.Span
+	start  time.Time
+	usage  Usage
+	err    error
+	closed bool
+}
+
+func (s *openAIStream) Recv() (Chunk, error) {
+	resp, err := s.stream.Recv()
+	if err != nil {
+		if !errors.Is(err, io.EOF) && s.err == nil {
+			s.err = err
+		}
+		return Chunk{}, err
+	}
+
+	var chunk Chunk
+	// With IncludeUsage the last chunk carries the token counts and no
+	// choices.
+	if resp.Usage != nil {
+		s.usage = usage(*resp.Usage)
+		chunk.Usage = &s.usage
+	}
+	if len(resp.Choices) > 0 {
+		chunk.Content = resp.Choices[0].Delta.Content
+	}
+	return chunk, nil
+}
+
+func (s *openAIStream) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	metrics.ObserveOpenAI(s.model, s.start, s.usage.PromptTokens, s.usage.CompletionTokens, s.err)
+	tracing.EndLLMSpan(s.span, s.usage.PromptTokens, s.usage.CompletionTokens, s.err)
+	return s.stream.Close()
+}
+
+func chatRequest(req Request) openai.ChatCompletionRequest {
+	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		messages[i] = openai.ChatCompletionMessage{Role: m.Role, Content: m.Content}
+	}
+	return openai.ChatCompletionRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+}
+
+func usage(u openai.Usage) Usage {
+	return Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+}
+
+// stripCodeFence unwraps a ```json block, which local models often put
+// around JSON answers.
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```")
+	if newline := strings.IndexByte(content, '\n'); newline >= 0 {
+		content = content[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
@@ -0,0 +1,20 @@
+package llm
+
+// messageOverhead is what the chat format adds to each message, in tokens.
+const messageOverhead = 4
+
+// EstimateTokens approximates the tokens in text at four bytes a token,
+// which is close for English and errs high for other scripts. It is meant
+// for budgeting, where a tokenizer per provider would be overkill.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// MessageTokens estimates the prompt tokens of messages.
+func MessageTokens(messages ...Message) int {
+	n := 0
+	for _, m := range messages {
+		n += messageOverhead + EstimateTokens(m.Content)
+	}
+	return n
+}
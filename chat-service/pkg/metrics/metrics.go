@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+var (
+	grpcServerHandling = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time taken to handle gRPC calls, by method and status code.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"grpc_method", "grpc_code"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gorm_query_duration_seconds",
+		Help:    "Latency of GORM operations, by operation and table.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"operation", "table"})
+
+	llmRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "openai_requests_total",
+		Help: "OpenAI requests, by model and outcome (ok or error).",
+	}, []string{"model", "outcome"})
+
+	llmDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "openai_request_duration_seconds",
+		Help:    "OpenAI request latency, by model. Streams are timed to their last chunk.",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 10),
+	}, []string{"model"})
+
+	llmTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "openai_tokens_total",
+		Help: "Tokens reported by OpenAI, by model and kind (prompt or completion).",
+	}, []string{"model", "kind"})
+)
+
+// Serve exposes /metrics on its own listener so it stays off the public
+// gRPC port.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		slog.Info("Metrics server is listening", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("Metrics server stopped", "err", err)
+		}
+	}()
+}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		grpcServerHandling.WithLabelValues(info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		grpcServerHandling.WithLabelValues(info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// ObserveOpenAI records one OpenAI request that started at start.
+func ObserveOpenAI(model string, start time.Time, promptTokens, completionTokens int, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	llmRequests.WithLabelValues(model, outcome).Inc()
+	llmDuration.WithLabelValues(model).Observe(time.Since(start).Seconds())
+	llmTokens.WithLabelValues(model, "prompt").Add(float64(promptTokens))
+	llmTokens.WithLabelValues(model, "completion").Add(float64(completionTokens))
+}
+
+// GormPlugin times every GORM operation. Register it with db.Use.
+type GormPlugin struct{}
+
+const startKey = "metrics:start"
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(startKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(startKey)
+			if !ok {
+				return
+			}
+			dbQueryDuration.WithLabelValues(operation, tx.Statement.Table).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+	}
+
+	cb := db.Callback()
+	register := []error{
+		cb.Create().Before("gorm:create").Register("metrics:before_create", before),
+		cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", before),
+		cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", before),
+		cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", before),
+		cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	}
+	for _, err := range register {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
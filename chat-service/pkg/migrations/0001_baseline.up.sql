@@ -0,0 +1,26 @@
+-- Baseline matching the schema GORM AutoMigrate created for the chat models.
+-- IF NOT EXISTS lets databases created by AutoMigrate adopt it unchanged.
+CREATE TABLE IF NOT EXISTS chats (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id    bigint,
+    name       text
+);
+CREATE INDEX IF NOT EXISTS idx_chats_deleted_at ON chats (deleted_at);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id    bigint,
+    sender     text,
+    body       text,
+    chat_id    bigint,
+    status     text DEFAULT 'complete',
+    CONSTRAINT fk_chats_messages FOREIGN KEY (chat_id) REFERENCES chats (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_messages_deleted_at ON messages (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_messages_chat_id ON messages (chat_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_chats_user_id_pinned;
+
+ALTER TABLE chats
+    DROP COLUMN IF EXISTS archived,
+    DROP COLUMN IF EXISTS pinned;
@@ -0,0 +1,6 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS pinned   boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS archived boolean NOT NULL DEFAULT false;
+
+-- Serves the summary list, which filters by user and sorts pinned first.
+CREATE INDEX IF NOT EXISTS idx_chats_user_id_pinned ON chats (user_id, pinned DESC, id DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE chats
+    DROP COLUMN IF EXISTS name_source;
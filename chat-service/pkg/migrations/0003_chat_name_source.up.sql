@@ -0,0 +1,6 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS name_source text NOT NULL DEFAULT 'prompt';
+
+-- Chats that were answered before titles were generated keep their names.
+UPDATE chats SET name_source = 'generated'
+WHERE EXISTS (SELECT 1 FROM messages WHERE messages.chat_id = chats.id AND messages.sender = 'AI');
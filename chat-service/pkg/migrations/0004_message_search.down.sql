@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_user_id;
+DROP INDEX IF EXISTS idx_messages_search_vector;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+-- Full-text search over message bodies. The column is generated, so the
+-- application never writes it and GORM leaves it out of the model.
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(body, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING gin (search_vector);
+
+-- Search filters by user before ranking.
+CREATE INDEX IF NOT EXISTS idx_messages_user_id ON messages (user_id);
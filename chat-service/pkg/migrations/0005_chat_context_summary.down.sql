@@ -0,0 +1,3 @@
+ALTER TABLE chats
+    DROP COLUMN IF EXISTS context_summary_until,
+    DROP COLUMN IF EXISTS context_summary;
@@ -0,0 +1,5 @@
+-- The running summary of turns that no longer fit the model's context, and
+-- the ID of the newest message folded into it.
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS context_summary       text   NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS context_summary_until bigint NOT NULL DEFAULT 0;
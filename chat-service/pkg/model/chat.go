@@ -1,16 +1,44 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Where a chat's name came from. A name taken from the first prompt is
+// replaced by a generated title after the first answer; a name the user
+// chose is never replaced.
+const (
+	ChatNameFromPrompt = "prompt"
+	ChatNameGenerated  = "generated"
+	ChatNameFromUser   = "user"
+)
 
 type Chat struct {
 	gorm.Model
-	ID       uint      `gorm:"primaryKey"`
-	UserID   uint      `json:"user_id"`
-	Name     string    `json:"name"`
-	Messages []Message `gorm:"foreignKey:ChatID;constraint:OnDelete:CASCADE"`
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `json:"user_id"`
+	Name       string    `json:"name"`
+	NameSource string    `gorm:"not null;default:prompt" json:"name_source"`
+	Pinned     bool      `gorm:"not null;default:false" json:"pinned"`
+	Archived   bool      `gorm:"not null;default:false" json:"archived"`
+	Messages   []Message `gorm:"foreignKey:ChatID;constraint:OnDelete:CASCADE"`
+
+	// ContextSummary sums up the turns up to and including message
+	// ContextSummaryUntil, which no longer fit the chat model's context.
+	ContextSummary      string `gorm:"not null;default:''" json:"-"`
+	ContextSummaryUntil uint   `gorm:"not null;default:0" json:"-"`
 }
 
+// ChatSummery is a chat as listed in the sidebar. UpdatedAt is the later of
+// the chat's own update and its newest message, and LastMessage is that
+// message's body.
 type ChatSummery struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Pinned      bool      `json:"pinned"`
+	Archived    bool      `json:"archived"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	LastMessage string    `json:"last_message"`
 }
@@ -1,6 +1,17 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MessageStatusComplete  = "complete"
+	MessageStatusStreaming = "streaming"
+	MessageStatusCancelled = "cancelled"
+	MessageStatusFailed    = "failed"
+)
 
 type Message struct {
 	gorm.Model
@@ -9,4 +20,24 @@ type Message struct {
 	Sender string `json:"sender"`
 	Body   string `json:"body"`
 	ChatID uint   `gorm:"index" json:"chat_id"`
+	Status string `gorm:"default:complete" json:"status"`
+}
+
+// Search snippets mark matched words with these bytes, which never occur
+// in chat text, until the service renders them as <mark> tags.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// MessageHit is a message found by a search, with a snippet of its body
+// around the matched words.
+type MessageHit struct {
+	MessageID uint      `json:"message_id"`
+	ChatID    uint      `json:"chat_id"`
+	ChatName  string    `json:"chat_name"`
+	Sender    string    `json:"sender"`
+	Snippet   string    `json:"snippet"`
+	Rank      float32   `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
 }
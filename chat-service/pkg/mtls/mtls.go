@@ -0,0 +1,211 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadInterval is how often the files are checked for a rotated
+// certificate. Checks happen lazily on handshakes.
+const reloadInterval = 30 * time.Second
+
+// Config locates the TLS material for one service. mTLS is off when
+// CertFile is empty, which keeps local setups without certificates working.
+type Config struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+
+	// AllowedPeers are the SANs (DNS names or URIs) a peer certificate must
+	// carry one of. Empty accepts any certificate signed by the CA.
+	AllowedPeers []string
+}
+
+func (c Config) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// Validate reports a partial setup, which would otherwise silently leave
+// mTLS off or fail on the first handshake.
+func (c Config) Validate() error {
+	if c.CAFile == "" && c.CertFile == "" && c.KeyFile == "" {
+		return nil
+	}
+	if c.CAFile == "" || c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("TLS_CA_FILE, TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	return nil
+}
+
+// Store holds the current certificate and CA pool. Rotated files are picked
+// up without a restart; if a reload fails the previous material stays in
+// use.
+type Store struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	roots    *x509.CertPool
+	modTimes map[string]time.Time
+	checked  time.Time
+}
+
+func NewStore(cfg Config) (*Store, error) {
+	if cfg.CAFile == "" || cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("mtls: CA, certificate and key files are all required")
+	}
+	s := &Store{cfg: cfg}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("mtls: load key pair: %w", err)
+	}
+	caPEM, err := os.ReadFile(s.cfg.CAFile)
+	if err != nil {
+		return fmt.Errorf("mtls: read CA bundle: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("mtls: no certificates in CA bundle %s", s.cfg.CAFile)
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, path := range []string{s.cfg.CAFile, s.cfg.CertFile, s.cfg.KeyFile} {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+
+	s.mu.Lock()
+	s.cert = &cert
+	s.roots = roots
+	s.modTimes = modTimes
+	s.checked = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Store) current() (*tls.Certificate, *x509.CertPool) {
+	s.maybeReload()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert, s.roots
+}
+
+func (s *Store) maybeReload() {
+	s.mu.Lock()
+	if time.Since(s.checked) < reloadInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.checked = time.Now()
+	changed := false
+	for path, modTime := range s.modTimes {
+		if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(modTime) {
+			changed = true
+		}
+	}
+	s.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err := s.load(); err != nil {
+		slog.Error("Keeping previous TLS material, reload failed", "err", err)
+		return
+	}
+	slog.Info("Reloaded rotated TLS material")
+}
+
+// ServerConfig requires clients to present a certificate signed by the CA
+// whose SANs include one of AllowedPeers.
+func (s *Store) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := s.current()
+			return cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, roots := s.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    roots,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				VerifyConnection: func(cs tls.ConnectionState) error {
+					return s.checkPeer(cs.PeerCertificates)
+				},
+			}, nil
+		},
+	}
+}
+
+// ClientConfig presents this service's certificate and verifies that the
+// server's certificate is valid for serverName. Verification is done by hand
+// so a rotated CA bundle applies to new connections.
+func (s *Store) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: true, // replaced by VerifyConnection below
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := s.current()
+			return cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("mtls: server presented no certificate")
+			}
+			_, roots := s.current()
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+				DNSName:       serverName,
+			})
+			if err != nil {
+				return fmt.Errorf("mtls: verify %s: %w", serverName, err)
+			}
+			return s.checkPeer(cs.PeerCertificates)
+		},
+	}
+}
+
+func (s *Store) checkPeer(certs []*x509.Certificate) error {
+	if len(s.cfg.AllowedPeers) == 0 {
+		return nil
+	}
+	if len(certs) == 0 {
+		return errors.New("mtls: peer presented no certificate")
+	}
+	leaf := certs[0]
+	var names []string
+	names = append(names, leaf.DNSNames...)
+	for _, uri := range leaf.URIs {
+		names = append(names, uri.String())
+	}
+	for _, name := range names {
+		for _, allowed := range s.cfg.AllowedPeers {
+			if name == allowed {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("mtls: peer %v is not one of %v", names, s.cfg.AllowedPeers)
+}
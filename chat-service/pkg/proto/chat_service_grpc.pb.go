@@ -23,9 +23,14 @@ const (
 	ChatService_CreateChat_FullMethodName          = "/proto.ChatService/CreateChat"
 	ChatService_GetRecentMessages_FullMethodName   = "/proto.ChatService/GetRecentMessages"
 	ChatService_StreamMessages_FullMethodName      = "/proto.ChatService/StreamMessages"
+	ChatService_GetMessageByID_FullMethodName      = "/proto.ChatService/GetMessageByID"
 	ChatService_GetChatSummariesUID_FullMethodName = "/proto.ChatService/GetChatSummariesUID"
 	ChatService_DeleteChatByID_FullMethodName      = "/proto.ChatService/DeleteChatByID"
 	ChatService_DeleteAllChatsByUID_FullMethodName = "/proto.ChatService/DeleteAllChatsByUID"
+	ChatService_RenameChat_FullMethodName          = "/proto.ChatService/RenameChat"
+	ChatService_PinChat_FullMethodName             = "/proto.ChatService/PinChat"
+	ChatService_ArchiveChat_FullMethodName         = "/proto.ChatService/ArchiveChat"
+	ChatService_SearchMessages_FullMethodName      = "/proto.ChatService/SearchMessages"
 )
 
 // ChatServiceClient is the client API for ChatService service.
@@ -36,9 +41,14 @@ type ChatServiceClient interface {
 	CreateChat(ctx context.Context, in *CreateChatRequest, opts ...grpc.CallOption) (*CreateChatResponse, error)
 	GetRecentMessages(ctx context.Context, in *GetRecentMessagesRequest, opts ...grpc.CallOption) (*GetRecentMessagesResponse, error)
 	StreamMessages(ctx context.Context, opts ...grpc.CallOption) (ChatService_StreamMessagesClient, error)
+	GetMessageByID(ctx context.Context, in *GetMessageByIDRequest, opts ...grpc.CallOption) (*GetMessageByIDResponse, error)
 	GetChatSummariesUID(ctx context.Context, in *GetChatSummariesUIDRequest, opts ...grpc.CallOption) (*GetChatSummariesUIDResponse, error)
 	DeleteChatByID(ctx context.Context, in *DeleteChatByIDRequest, opts ...grpc.CallOption) (*DeleteChatByIDResponse, error)
 	DeleteAllChatsByUID(ctx context.Context, in *DeleteAllChatsByUIDRequest, opts ...grpc.CallOption) (*DeleteAllChatsByUIDResponse, error)
+	RenameChat(ctx context.Context, in *RenameChatRequest, opts ...grpc.CallOption) (*RenameChatResponse, error)
+	PinChat(ctx context.Context, in *PinChatRequest, opts ...grpc.CallOption) (*PinChatResponse, error)
+	ArchiveChat(ctx context.Context, in *ArchiveChatRequest, opts ...grpc.CallOption) (*ArchiveChatResponse, error)
+	SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error)
 }
 
 type chatServiceClient struct {
@@ -107,6 +117,15 @@ func (x *chatServiceStreamMessagesClient) Recv() (*CreateMessageResponse, error)
 	return m, nil
 }
 
+func (c *chatServiceClient) GetMessageByID(ctx context.Context, in *GetMessageByIDRequest, opts ...grpc.CallOption) (*GetMessageByIDResponse, error) {
+	out := new(GetMessageByIDResponse)
+	err := c.cc.Invoke(ctx, ChatService_GetMessageByID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *chatServiceClient) GetChatSummariesUID(ctx context.Context, in *GetChatSummariesUIDRequest, opts ...grpc.CallOption) (*GetChatSummariesUIDResponse, error) {
 	out := new(GetChatSummariesUIDResponse)
 	err := c.cc.Invoke(ctx, ChatService_GetChatSummariesUID_FullMethodName, in, out, opts...)
@@ -134,6 +153,42 @@ func (c *chatServiceClient) DeleteAllChatsByUID(ctx context.Context, in *DeleteA
 	return out, nil
 }
 
+func (c *chatServiceClient) RenameChat(ctx context.Context, in *RenameChatRequest, opts ...grpc.CallOption) (*RenameChatResponse, error) {
+	out := new(RenameChatResponse)
+	err := c.cc.Invoke(ctx, ChatService_RenameChat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) PinChat(ctx context.Context, in *PinChatRequest, opts ...grpc.CallOption) (*PinChatResponse, error) {
+	out := new(PinChatResponse)
+	err := c.cc.Invoke(ctx, ChatService_PinChat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ArchiveChat(ctx context.Context, in *ArchiveChatRequest, opts ...grpc.CallOption) (*ArchiveChatResponse, error) {
+	out := new(ArchiveChatResponse)
+	err := c.cc.Invoke(ctx, ChatService_ArchiveChat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error) {
+	out := new(SearchMessagesResponse)
+	err := c.cc.Invoke(ctx, ChatService_SearchMessages_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ChatServiceServer is the server API for ChatService service.
 // All implementations must embed UnimplementedChatServiceServer
 // for forward compatibility
@@ -142,9 +197,14 @@ type ChatServiceServer interface {
 	CreateChat(context.Context, *CreateChatRequest) (*CreateChatResponse, error)
 	GetRecentMessages(context.Context, *GetRecentMessagesRequest) (*GetRecentMessagesResponse, error)
 	StreamMessages(ChatService_StreamMessagesServer) error
+	GetMessageByID(context.Context, *GetMessageByIDRequest) (*GetMessageByIDResponse, error)
 	GetChatSummariesUID(context.Context, *GetChatSummariesUIDRequest) (*GetChatSummariesUIDResponse, error)
 	DeleteChatByID(context.Context, *DeleteChatByIDRequest) (*DeleteChatByIDResponse, error)
 	DeleteAllChatsByUID(context.Context, *DeleteAllChatsByUIDRequest) (*DeleteAllChatsByUIDResponse, error)
+	RenameChat(context.Context, *RenameChatRequest) (*RenameChatResponse, error)
+	PinChat(context.Context, *PinChatRequest) (*PinChatResponse, error)
+	ArchiveChat(context.Context, *ArchiveChatRequest) (*ArchiveChatResponse, error)
+	SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error)
 	mustEmbedUnimplementedChatServiceServer()
 }
 
@@ -164,6 +224,9 @@ func (UnimplementedChatServiceServer) GetRecentMessages(context.Context, *GetRec
 func (UnimplementedChatServiceServer) StreamMessages(ChatService_StreamMessagesServer) error {
 	return status.Errorf(codes.Unimplemented, "method StreamMessages not implemented")
 }
+func (UnimplementedChatServiceServer) GetMessageByID(context.Context, *GetMessageByIDRequest) (*GetMessageByIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessageByID not implemented")
+}
 func (UnimplementedChatServiceServer) GetChatSummariesUID(context.Context, *GetChatSummariesUIDRequest) (*GetChatSummariesUIDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChatSummariesUID not implemented")
 }
@@ -173,6 +236,18 @@ func (UnimplementedChatServiceServer) DeleteChatByID(context.Context, *DeleteCha
 func (UnimplementedChatServiceServer) DeleteAllChatsByUID(context.Context, *DeleteAllChatsByUIDRequest) (*DeleteAllChatsByUIDResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteAllChatsByUID not implemented")
 }
+func (UnimplementedChatServiceServer) RenameChat(context.Context, *RenameChatRequest) (*RenameChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameChat not implemented")
+}
+func (UnimplementedChatServiceServer) PinChat(context.Context, *PinChatRequest) (*PinChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinChat not implemented")
+}
+func (UnimplementedChatServiceServer) ArchiveChat(context.Context, *ArchiveChatRequest) (*ArchiveChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveChat not implemented")
+}
+func (UnimplementedChatServiceServer) SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMessages not implemented")
+}
 func (UnimplementedChatServiceServer) mustEmbedUnimplementedChatServiceServer() {}
 
 // UnsafeChatServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -266,6 +341,24 @@ func (x *chatServiceStreamMessagesServer) Recv() (*CreateMessageRequest, error)
 	return m, nil
 }
 
+func _ChatService_GetMessageByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetMessageByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetMessageByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetMessageByID(ctx, req.(*GetMessageByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ChatService_GetChatSummariesUID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetChatSummariesUIDRequest)
 	if err := dec(in); err != nil {
@@ -320,6 +413,78 @@ func _ChatService_DeleteAllChatsByUID_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatService_RenameChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RenameChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_RenameChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RenameChat(ctx, req.(*RenameChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_PinChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).PinChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_PinChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).PinChat(ctx, req.(*PinChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ArchiveChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ArchiveChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_ArchiveChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ArchiveChat(ctx, req.(*ArchiveChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SearchMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SearchMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_SearchMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SearchMessages(ctx, req.(*SearchMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ChatService_ServiceDesc is the grpc.ServiceDesc for ChatService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -339,6 +504,10 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetRecentMessages",
 			Handler:    _ChatService_GetRecentMessages_Handler,
 		},
+		{
+			MethodName: "GetMessageByID",
+			Handler:    _ChatService_GetMessageByID_Handler,
+		},
 		{
 			MethodName: "GetChatSummariesUID",
 			Handler:    _ChatService_GetChatSummariesUID_Handler,
@@ -351,6 +520,22 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAllChatsByUID",
 			Handler:    _ChatService_DeleteAllChatsByUID_Handler,
 		},
+		{
+			MethodName: "RenameChat",
+			Handler:    _ChatService_RenameChat_Handler,
+		},
+		{
+			MethodName: "PinChat",
+			Handler:    _ChatService_PinChat_Handler,
+		},
+		{
+			MethodName: "ArchiveChat",
+			Handler:    _ChatService_ArchiveChat_Handler,
+		},
+		{
+			MethodName: "SearchMessages",
+			Handler:    _ChatService_SearchMessages_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2,50 +2,139 @@ package repository
 
 import (
 	"chat-service/pkg/model"
+	"context"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-type ChatRepository struct {
+// ChatRepository stores chats. Deleting a chat deletes its messages.
+type ChatRepository interface {
+	CreateNewChat(ctx context.Context, chat *model.Chat) error
+	UpdateChat(ctx context.Context, chat *model.Chat) error
+	// FindChatByID returns one of the user's chats.
+	FindChatByID(ctx context.Context, chatID, userID uint) (*model.Chat, error)
+	// GetAllChatSummeryByUserID lists pinned chats first, then the rest,
+	// newest first. Archived chats are left out unless includeArchived.
+	GetAllChatSummeryByUserID(ctx context.Context, userID uint, includeArchived bool) ([]model.ChatSummery, error)
+	GetChatSummary(ctx context.Context, chatID, userID uint) (*model.ChatSummery, error)
+	// The UpdateChat* methods change one of the user's chats and return
+	// gorm.ErrRecordNotFound when the user has no such chat.
+	// UpdateChatName records the name's source; a name that is not from the
+	// user only replaces one taken from the prompt, so it never overwrites a
+	// name the user chose.
+	UpdateChatName(ctx context.Context, chatID, userID uint, name, source string) error
+	UpdateChatPinned(ctx context.Context, chatID, userID uint, pinned bool) error
+	UpdateChatArchived(ctx context.Context, chatID, userID uint, archived bool) error
+	// UpdateContextSummary replaces the running summary of one of the user's
+	// chats, which covered messages up to fromMessageID, with one covering
+	// messages up to untilMessageID. It returns gorm.ErrRecordNotFound when
+	// the summary was moved on concurrently.
+	UpdateContextSummary(ctx context.Context, chatID, userID, fromMessageID, untilMessageID uint, summary string) error
+	DeleteChatByID(ctx context.Context, chatID, userID uint) error
+	DeleteAllChatsByUID(ctx context.Context, userID uint) error
+}
+
+type GormChatRepository struct {
 	db *gorm.DB
 }
 
-func NewChatRepository(db *gorm.DB) *ChatRepository {
-	return &ChatRepository{db: db}
+func NewChatRepository(db *gorm.DB) *GormChatRepository {
+	return &GormChatRepository{db: db}
 }
 
-func (repo *ChatRepository) CreateNewChat(chat *model.Chat) error {
-	return repo.db.Create(chat).Error
+func (repo *GormChatRepository) CreateNewChat(ctx context.Context, chat *model.Chat) error {
+	return repo.db.WithContext(ctx).Create(chat).Error
 }
 
-func (repo *ChatRepository) UpdateChat(chat *model.Chat) error {
-	return repo.db.Save(chat).Error
+func (repo *GormChatRepository) UpdateChat(ctx context.Context, chat *model.Chat) error {
+	return repo.db.WithContext(ctx).Save(chat).Error
 }
 
-func (repo *ChatRepository) FindChatByID(chatID uint) (*model.Chat, error) {
+func (repo *GormChatRepository) FindChatByID(ctx context.Context, chatID, userID uint) (*model.Chat, error) {
 	var chat model.Chat
-	result := repo.db.First(&chat, chatID)
+	result := repo.db.WithContext(ctx).Where("id = ? AND user_id = ?", chatID, userID).First(&chat)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &chat, nil
 }
 
-func (repo *ChatRepository) GetAllChatSummeryByUserID(userID uint) ([]model.ChatSummery, error) {
+func (repo *GormChatRepository) GetAllChatSummeryByUserID(ctx context.Context, userID uint, includeArchived bool) ([]model.ChatSummery, error) {
 	var chats []model.ChatSummery
-	results := repo.db.Model(&model.Chat{}).Select("id", "name").Where("user_id = ?", userID).Order("id DESC")
+	query := repo.summaries(ctx).Where("chats.user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("NOT chats.archived")
+	}
 
-	if err := results.Find(&chats).Error; err != nil {
+	if err := query.Order("chats.pinned DESC, chats.id DESC").Find(&chats).Error; err != nil {
 		return nil, err
 	}
 	return chats, nil
 }
 
-func (repo *ChatRepository) DeleteChatByID(chatID, userID uint) error {
-	return repo.db.Where("id = ? AND user_id = ?", chatID, userID).Select(clause.Associations).Delete(&model.Chat{}).Error
+func (repo *GormChatRepository) GetChatSummary(ctx context.Context, chatID, userID uint) (*model.ChatSummery, error) {
+	var chat model.ChatSummery
+	result := repo.summaries(ctx).Where("chats.id = ? AND chats.user_id = ?", chatID, userID).Take(&chat)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &chat, nil
+}
+
+// summaries selects chats with their newest message joined in.
+func (repo *GormChatRepository) summaries(ctx context.Context) *gorm.DB {
+	return repo.db.WithContext(ctx).Model(&model.Chat{}).
+		Select("chats.id, chats.name, chats.pinned, chats.archived, " +
+			"GREATEST(chats.updated_at, last_message.updated_at) AS updated_at, " +
+			"COALESCE(last_message.body, '') AS last_message").
+		Joins("LEFT JOIN LATERAL (SELECT body, updated_at FROM messages " +
+			"WHERE messages.chat_id = chats.id AND messages.deleted_at IS NULL " +
+			"ORDER BY messages.id DESC LIMIT 1) AS last_message ON true")
+}
+
+func (repo *GormChatRepository) UpdateChatName(ctx context.Context, chatID, userID uint, name, source string) error {
+	query := repo.db.WithContext(ctx).Model(&model.Chat{}).Where("id = ? AND user_id = ?", chatID, userID)
+	if source != model.ChatNameFromUser {
+		query = query.Where("name_source = ?", model.ChatNameFromPrompt)
+	}
+	return updated(query.Updates(map[string]interface{}{"name": name, "name_source": source}))
+}
+
+func (repo *GormChatRepository) UpdateChatPinned(ctx context.Context, chatID, userID uint, pinned bool) error {
+	return repo.updateChat(ctx, chatID, userID, "pinned", pinned)
+}
+
+func (repo *GormChatRepository) UpdateChatArchived(ctx context.Context, chatID, userID uint, archived bool) error {
+	return repo.updateChat(ctx, chatID, userID, "archived", archived)
+}
+
+func (repo *GormChatRepository) updateChat(ctx context.Context, chatID, userID uint, column string, value interface{}) error {
+	return updated(repo.db.WithContext(ctx).Model(&model.Chat{}).Where("id = ? AND user_id = ?", chatID, userID).Update(column, value))
+}
+
+// updated reports an update that matched no rows as gorm.ErrRecordNotFound.
+func updated(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (repo *GormChatRepository) UpdateContextSummary(ctx context.Context, chatID, userID, fromMessageID, untilMessageID uint, summary string) error {
+	// The summary is internal, so it does not count as a change to the chat.
+	return updated(repo.db.WithContext(ctx).Model(&model.Chat{}).
+		Where("id = ? AND user_id = ? AND context_summary_until = ?", chatID, userID, fromMessageID).
+		UpdateColumns(map[string]interface{}{"context_summary": summary, "context_summary_until": untilMessageID}))
+}
+
+func (repo *GormChatRepository) DeleteChatByID(ctx context.Context, chatID, userID uint) error {
+	return repo.db.WithContext(ctx).Where("id = ? AND user_id = ?", chatID, userID).Select(clause.Associations).Delete(&model.Chat{}).Error
 }
 
-func (repo *ChatRepository) DeleteAllChatsByUID(userID uint) error {
-	return repo.db.Where("user_id = ?", userID).Select(clause.Associations).Delete(&model.Chat{}).Error
+func (repo *GormChatRepository) DeleteAllChatsByUID(ctx context.Context, userID uint) error {
+	return repo.db.WithContext(ctx).Where("user_id = ?", userID).Select(clause.Associations).Delete(&model.Chat{}).Error
 }
@@ -0,0 +1,442 @@
+package repository
+
+import (
+	"chat-service/pkg/model"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+var (
+	_ ChatRepository    = (*GormChatRepository)(nil)
+	_ MessageRepository = (*GormMessageRepository)(nil)
+	_ ChatRepository    = (*memoryChatRepository)(nil)
+	_ MessageRepository = (*memoryMessageRepository)(nil)
+)
+
+// MemoryDB is an in-memory stand-in for the chat database, for running the
+// services without Postgres. Its repositories behave like the GORM ones:
+// IDs and timestamps are assigned on create, messages must belong to an
+// existing chat, lookups of missing rows return gorm.ErrRecordNotFound,
+// listings come back in the same order and deleting a chat deletes its
+// messages.
+type MemoryDB struct {
+	mu       sync.Mutex
+	chats    map[uint]model.Chat
+	messages map[uint]model.Message
+	lastID   map[string]uint
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		chats:    make(map[uint]model.Chat),
+		messages: make(map[uint]model.Message),
+		lastID:   make(map[string]uint),
+	}
+}
+
+func (db *MemoryDB) ChatRepository() ChatRepository {
+	return &memoryChatRepository{db: db}
+}
+
+func (db *MemoryDB) MessageRepository() MessageRepository {
+	return &memoryMessageRepository{db: db}
+}
+
+// nextID hands out IDs per table like a bigserial column. Callers hold mu.
+func (db *MemoryDB) nextID(table string) uint {
+	db.lastID[table]++
+	return db.lastID[table]
+}
+
+// insertMessage stores a copy of message, assigning its ID and timestamps.
+// Callers hold mu.
+func (db *MemoryDB) insertMessage(message *model.Message) error {
+	if _, ok := db.chats[message.ChatID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	now := time.Now()
+	if message.ID == 0 {
+		message.ID = db.nextID("messages")
+	}
+	message.CreatedAt, message.UpdatedAt = now, now
+	if message.Status == "" {
+		message.Status = model.MessageStatusComplete
+	}
+	db.messages[message.ID] = *message
+	return nil
+}
+
+type memoryChatRepository struct {
+	db *MemoryDB
+}
+
+func (repo *memoryChatRepository) CreateNewChat(ctx context.Context, chat *model.Chat) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	now := time.Now()
+	chat.ID = repo.db.nextID("chats")
+	chat.CreatedAt, chat.UpdatedAt = now, now
+	if chat.NameSource == "" {
+		chat.NameSource = model.ChatNameFromPrompt
+	}
+
+	stored := *chat
+	stored.Messages = nil
+	repo.db.chats[chat.ID] = stored
+
+	for i := range chat.Messages {
+		chat.Messages[i].ChatID = chat.ID
+		if err := repo.db.insertMessage(&chat.Messages[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *memoryChatRepository) UpdateChat(ctx context.Context, chat *model.Chat) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if chat.ID == 0 {
+		chat.ID = repo.db.nextID("chats")
+		chat.CreatedAt = time.Now()
+	}
+	chat.UpdatedAt = time.Now()
+
+	stored := *chat
+	stored.Messages = nil
+	repo.db.chats[chat.ID] = stored
+	return nil
+}
+
+func (repo *memoryChatRepository) FindChatByID(ctx context.Context, chatID, userID uint) (*model.Chat, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	chat, ok := repo.db.chats[chatID]
+	if !ok || chat.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &chat, nil
+}
+
+func (repo *memoryChatRepository) GetAllChatSummeryByUserID(ctx context.Context, userID uint, includeArchived bool) ([]model.ChatSummery, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var chats []model.ChatSummery
+	for _, chat := range repo.db.chats {
+		if chat.UserID == userID && (includeArchived || !chat.Archived) {
+			chats = append(chats, repo.summary(chat))
+		}
+	}
+	sort.Slice(chats, func(i, j int) bool {
+		if chats[i].Pinned != chats[j].Pinned {
+			return chats[i].Pinned
+		}
+		return chats[i].ID > chats[j].ID
+	})
+	return chats, nil
+}
+
+func (repo *memoryChatRepository) GetChatSummary(ctx context.Context, chatID, userID uint) (*model.ChatSummery, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	chat, ok := repo.db.chats[chatID]
+	if !ok || chat.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	summary := repo.summary(chat)
+	return &summary, nil
+}
+
+// summary joins in the chat's newest message. Callers hold mu.
+func (repo *memoryChatRepository) summary(chat model.Chat) model.ChatSummery {
+	summary := model.ChatSummery{ID: chat.ID, Name: chat.Name, Pinned: chat.Pinned, Archived: chat.Archived, UpdatedAt: chat.UpdatedAt}
+	var last model.Message
+	for _, message := range repo.db.messages {
+		if message.ChatID == chat.ID && message.ID > last.ID {
+			last = message
+		}
+	}
+	if last.ID != 0 {
+		summary.LastMessage = last.Body
+		if last.UpdatedAt.After(summary.UpdatedAt) {
+			summary.UpdatedAt = last.UpdatedAt
+		}
+	}
+	return summary
+}
+
+func (repo *memoryChatRepository) UpdateChatName(ctx context.Context, chatID, userID uint, name, source string) error {
+	return repo.update(chatID, userID, func(chat *model.Chat) bool {
+		if source != model.ChatNameFromUser && chat.NameSource != model.ChatNameFromPrompt {
+			return false
+		}
+		chat.Name, chat.NameSource = name, source
+		return true
+	})
+}
+
+func (repo *memoryChatRepository) UpdateChatPinned(ctx context.Context, chatID, userID uint, pinned bool) error {
+	return repo.update(chatID, userID, func(chat *model.Chat) bool {
+		chat.Pinned = pinned
+		return true
+	})
+}
+
+func (repo *memoryChatRepository) UpdateChatArchived(ctx context.Context, chatID, userID uint, archived bool) error {
+	return repo.update(chatID, userID, func(chat *model.Chat) bool {
+		chat.Archived = archived
+		return true
+	})
+}
+
+// update applies fn to one of the user's chats. fn reports false when the
+// chat does not match the update's conditions.
+func (repo *memoryChatRepository) update(chatID, userID uint, fn func(chat *model.Chat) bool) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	chat, ok := repo.db.chats[chatID]
+	if !ok || chat.UserID != userID || !fn(&chat) {
+		return gorm.ErrRecordNotFound
+	}
+	chat.UpdatedAt = time.Now()
+	repo.db.chats[chatID] = chat
+	return nil
+}
+
+func (repo *memoryChatRepository) UpdateContextSummary(ctx context.Context, chatID, userID, fromMessageID, untilMessageID uint, summary string) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	chat, ok := repo.db.chats[chatID]
+	if !ok || chat.UserID != userID || chat.ContextSummaryUntil != fromMessageID {
+		return gorm.ErrRecordNotFound
+	}
+	chat.ContextSummary, chat.ContextSummaryUntil = summary, untilMessageID
+	repo.db.chats[chatID] = chat
+	return nil
+}
+
+func (repo *memoryChatRepository) DeleteChatByID(ctx context.Context, chatID, userID uint) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if chat, ok := repo.db.chats[chatID]; ok && chat.UserID == userID {
+		repo.deleteChat(chatID)
+	}
+	return nil
+}
+
+func (repo *memoryChatRepository) DeleteAllChatsByUID(ctx context.Context, userID uint) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	for id, chat := range repo.db.chats {
+		if chat.UserID == userID {
+			repo.deleteChat(id)
+		}
+	}
+	return nil
+}
+
+func (repo *memoryChatRepository) deleteChat(chatID uint) {
+	delete(repo.db.chats, chatID)
+	for id, message := range repo.db.messages {
+		if message.ChatID == chatID {
+			delete(repo.db.messages, id)
+		}
+	}
+}
+
+type memoryMessageRepository struct {
+	db *MemoryDB
+}
+
+func (repo *memoryMessageRepository) CreateNewMessage(ctx context.Context, message *model.Message) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	return repo.db.insertMessage(message)
+}
+
+func (repo *memoryMessageRepository) UpdateMessageContent(ctx context.Context, messageID uint, content string) error {
+	return repo.update(messageID, func(message *model.Message) { message.Body = content })
+}
+
+func (repo *memoryMessageRepository) FindMessageByID(ctx context.Context, messageID, userID uint) (*model.Message, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	message, ok := repo.db.messages[messageID]
+	if !ok || message.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &message, nil
+}
+
+func (repo *memoryMessageRepository) UpdateMessageStatus(ctx context.Context, messageID uint, status string) error {
+	return repo.update(messageID, func(message *model.Message) { message.Status = status })
+}
+
+func (repo *memoryMessageRepository) FindMessagesByChatIDPaginated(ctx context.Context, chatID uint, lastMessageID uint, limit uint) ([]model.Message, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var messages []model.Message
+	for _, message := range repo.db.messages {
+		if message.ChatID == chatID && (lastMessageID == 0 || message.ID < lastMessageID) {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID > messages[j].ID })
+	if uint(len(messages)) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+func (repo *memoryMessageRepository) FindMessagesSince(ctx context.Context, chatID, afterMessageID, limit uint) ([]model.Message, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var messages []model.Message
+	for _, message := range repo.db.messages {
+		if message.ChatID == chatID && message.ID > afterMessageID {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	if uint(len(messages)) > limit {
+		messages = messages[uint(len(messages))-limit:]
+	}
+	return messages, nil
+}
+
+func (repo *memoryMessageRepository) DeleteMessageByMessageID(ctx context.Context, messageID uint) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	delete(repo.db.messages, messageID)
+	return nil
+}
+
+// SearchMessages approximates the Postgres search: every query word must
+// start a word of the body, and messages rank by how many words match.
+// Quotes and operators in the query are ignored.
+func (repo *memoryMessageRepository) SearchMessages(ctx context.Context, userID uint, query string, limit, offset uint) ([]model.MessageHit, error) {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var hits []model.MessageHit
+	for _, message := range repo.db.messages {
+		if message.UserID != userID {
+			continue
+		}
+		words := strings.Fields(message.Body)
+		matched := make([]bool, len(words))
+		found := map[string]bool{}
+		for i, word := range words {
+			for _, part := range searchWords(word) {
+				for _, term := range terms {
+					if strings.HasPrefix(part, term) {
+						matched[i] = true
+						found[term] = true
+					}
+				}
+			}
+		}
+		if len(found) < len(terms) {
+			continue
+		}
+
+		var rank float32
+		first := -1
+		for i := range words {
+			if matched[i] {
+				rank++
+				if first < 0 {
+					first = i
+				}
+				words[i] = model.HighlightStart + words[i] + model.HighlightEnd
+			}
+		}
+		start, end := max(first-10, 0), min(first+20, len(words))
+		snippet := strings.Join(words[start:end], " ")
+		if start > 0 {
+			snippet = "… " + snippet
+		}
+		if end < len(words) {
+			snippet += " …"
+		}
+
+		hits = append(hits, model.MessageHit{
+			MessageID: message.ID,
+			ChatID:    message.ChatID,
+			ChatName:  repo.db.chats[message.ChatID].Name,
+			Sender:    message.Sender,
+			Snippet:   snippet,
+			Rank:      rank / float32(len(words)),
+			CreatedAt: message.CreatedAt,
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].MessageID > hits[j].MessageID
+	})
+	if offset >= uint(len(hits)) {
+		return nil, nil
+	}
+	hits = hits[offset:]
+	if uint(len(hits)) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// searchWords lowercases text and splits it into words, dropping
+// punctuation and the "or" operator.
+func searchWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if word != "or" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// update applies fn to a stored message. Like an UPDATE matching no rows,
+// a missing message is not an error.
+func (repo *memoryMessageRepository) update(messageID uint, fn func(message *model.Message)) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	message, ok := repo.db.messages[messageID]
+	if !ok {
+		return nil
+	}
+	fn(&message)
+	message.UpdatedAt = time.Now()
+	repo.db.messages[messageID] = message
+	return nil
+}
@@ -2,77 +2,111 @@ package repository
 
 import (
 	"chat-service/pkg/model"
-	"sync"
+	"context"
 
 	"gorm.io/gorm"
 )
 
-type MessageRepository struct {
+// MessageRepository stores the messages of chats.
+type MessageRepository interface {
+	CreateNewMessage(ctx context.Context, message *model.Message) error
+	UpdateMessageContent(ctx context.Context, messageID uint, content string) error
+	FindMessageByID(ctx context.Context, messageID, userID uint) (*model.Message, error)
+	UpdateMessageStatus(ctx context.Context, messageID uint, status string) error
+	// FindMessagesByChatIDPaginated returns up to limit messages older than
+	// lastMessageID, newest first. A lastMessageID of 0 starts at the newest.
+	FindMessagesByChatIDPaginated(ctx context.Context, chatID uint, lastMessageID uint, limit uint) ([]model.Message, error)
+	// FindMessagesSince returns up to limit of the newest messages after
+	// afterMessageID, oldest first.
+	FindMessagesSince(ctx context.Context, chatID, afterMessageID, limit uint) ([]model.Message, error)
+	DeleteMessageByMessageID(ctx context.Context, messageID uint) error
+	// SearchMessages finds the user's messages matching a web search style
+	// query, best matches first, skipping offset hits.
+	SearchMessages(ctx context.Context, userID uint, query string, limit, offset uint) ([]model.MessageHit, error)
+}
+
+type GormMessageRepository struct {
 	db *gorm.DB
 }
 
-func NewMessageRepository(db *gorm.DB) *MessageRepository {
-	return &MessageRepository{db: db}
+func NewMessageRepository(db *gorm.DB) *GormMessageRepository {
+	return &GormMessageRepository{db: db}
 }
 
-func (repo *MessageRepository) CreateNewMessage(message *model.Message) error {
-	return repo.db.Create(message).Error
+func (repo *GormMessageRepository) CreateNewMessage(ctx context.Context, message *model.Message) error {
+	return repo.db.WithContext(ctx).Create(message).Error
 }
 
-func (repo *MessageRepository) UpdateMessageContent(messageID uint, content string) error {
-	return repo.db.Model(&model.Message{}).Where("id = ?", messageID).Update("body", content).Error
+func (repo *GormMessageRepository) UpdateMessageContent(ctx context.Context, messageID uint, content string) error {
+	return repo.db.WithContext(ctx).Model(&model.Message{}).Where("id = ?", messageID).Update("body", content).Error
 }
 
-func (repo *MessageRepository) FindMessagesByChatIDPaginated(chatID uint, lastMessageID uint, limit uint) ([]model.Message, error) {
-	var messages []model.Message
-	var wg sync.WaitGroup
-	msgCh := make(chan []model.Message, 20)
-	errCh := make(chan error, 1)
+func (repo *GormMessageRepository) FindMessageByID(ctx context.Context, messageID, userID uint) (*model.Message, error) {
+	var message model.Message
+	result := repo.db.WithContext(ctx).Where("id = ? AND user_id = ?", messageID, userID).First(&message)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &message, nil
+}
+
+func (repo *GormMessageRepository) UpdateMessageStatus(ctx context.Context, messageID uint, status string) error {
+	return repo.db.WithContext(ctx).Model(&model.Message{}).Where("id = ?", messageID).Update("status", status).Error
+}
 
-	fetchMessages := func(chatID, lastMessageID, limit uint) {
-		defer wg.Done()
-		var msgs []model.Message
+func (repo *GormMessageRepository) FindMessagesByChatIDPaginated(ctx context.Context, chatID uint, lastMessageID uint, limit uint) ([]model.Message, error) {
+	var messages []model.Message
+	query := repo.db.WithContext(ctx).Where("chat_id = ?", chatID)
+	if lastMessageID != 0 {
+		query = query.Where("id < ?", lastMessageID)
+	}
+	if err := query.Order("id DESC").Limit(int(limit)).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
 
-		query := repo.db.Where("chat_id = ? AND id < ?", chatID, lastMessageID).Order("id DESC").Limit(int(limit))
-		if lastMessageID == 0 {
-			query = repo.db.Where("chat_id = ?", chatID).Order("id DESC").Limit(int(limit))
-		}
-		result := query.Find(&msgs)
-		if result.Error != nil {
-			errCh <- result.Error
-			return
-		}
-		msgCh <- msgs
+func (repo *GormMessageRepository) FindMessagesSince(ctx context.Context, chatID, afterMessageID, limit uint) ([]model.Message, error) {
+	var messages []model.Message
+	newest := repo.db.WithContext(ctx).Model(&model.Message{}).
+		Where("chat_id = ? AND id > ?", chatID, afterMessageID).
+		Order("id DESC").
+		Limit(int(limit))
+	if err := repo.db.WithContext(ctx).Table("(?) AS messages", newest).Order("id").Find(&messages).Error; err != nil {
+		return nil, err
 	}
+	return messages, nil
+}
 
-	wg.Add(1)
+func (repo *GormMessageRepository) DeleteMessageByMessageID(ctx context.Context, messageID uint) error {
+	return repo.db.WithContext(ctx).Where("id = ?", messageID).Delete(&model.Message{}).Error
+}
 
-	go fetchMessages(chatID, lastMessageID, limit)
+// headlineOptions asks ts_headline for up to two short fragments with the
+// matched words between model.HighlightStart and model.HighlightEnd.
+const headlineOptions = "StartSel=" + model.HighlightStart + ", StopSel=" + model.HighlightEnd +
+	", MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=\" … \""
 
-	go func() {
-		wg.Wait()
-		close(msgCh)
-		close(errCh)
-	}()
+func (repo *GormMessageRepository) SearchMessages(ctx context.Context, userID uint, query string, limit, offset uint) ([]model.MessageHit, error) {
+	hits := repo.db.WithContext(ctx).Table("messages").
+		Select("messages.id AS message_id, messages.chat_id, chats.name AS chat_name, messages.sender, messages.body, messages.created_at, "+
+			"ts_rank(messages.search_vector, query) AS rank, query").
+		Joins("JOIN chats ON chats.id = messages.chat_id AND chats.deleted_at IS NULL").
+		Joins("CROSS JOIN websearch_to_tsquery('english', ?) AS query", query).
+		Where("messages.user_id = ? AND messages.deleted_at IS NULL AND messages.search_vector @@ query", userID).
+		Order("rank DESC, messages.id DESC").
+		Limit(int(limit)).
+		Offset(int(offset))
 
-	for {
-		select {
-		case msgs, ok := <-msgCh:
-			if ok {
-				messages = append(messages, msgs...)
-			}
-		case err, ok := <-errCh:
-			if ok {
-				return nil, err
-			}
-		default:
-			if len(msgCh) == 0 && len(errCh) == 0 {
-				return messages, nil
-			}
-		}
+	// Headlines are costly, so only the page of hits gets one.
+	var results []model.MessageHit
+	err := repo.db.WithContext(ctx).Table("(?) AS hits", hits).
+		Select("hits.message_id, hits.chat_id, hits.chat_name, hits.sender, hits.created_at, hits.rank, "+
+			"ts_headline('english', hits.body, hits.query, ?) AS snippet", headlineOptions).
+		Order("hits.rank DESC, hits.message_id DESC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
 	}
-}
-
-func (repo *MessageRepository) DeleteMessageByMessageID(messageID uint) error {
-	return repo.db.Where("id = ?", messageID).Delete(&model.Message{}).Error
+	return results, nil
 }
@@ -0,0 +1,300 @@
+package repository_test
+
+import (
+	"chat-service/pkg/migrate"
+	"chat-service/pkg/migrations"
+	"chat-service/pkg/model"
+	"chat-service/pkg/repository"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The contract below runs against both implementations, so the in-memory
+// repositories used by the server tests keep behaving like the GORM ones.
+
+type repositories struct {
+	chats    repository.ChatRepository
+	messages repository.MessageRepository
+}
+
+func TestMemoryRepositories(t *testing.T) {
+	testRepositories(t, func(t *testing.T) repositories {
+		db := repository.NewMemoryDB()
+		return repositories{chats: db.ChatRepository(), messages: db.MessageRepository()}
+	})
+}
+
+func TestGormRepositories(t *testing.T) {
+	testRepositories(t, func(t *testing.T) repositories {
+		db := openTestDB(t)
+		return repositories{chats: repository.NewChatRepository(db), messages: repository.NewMessageRepository(db)}
+	})
+}
+
+func testRepositories(t *testing.T, open func(t *testing.T) repositories) {
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, open(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, open(t)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, open(t)) })
+	t.Run("UserScoping", func(t *testing.T) { testUserScoping(t, open(t)) })
+}
+
+func testOrdering(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	older := createChat(t, repos, 1, "older", "first")
+	pinned := createChat(t, repos, 1, "pinned", "second")
+	newer := createChat(t, repos, 1, "newer", "third")
+	archived := createChat(t, repos, 1, "archived", "fourth")
+	if err := repos.chats.UpdateChatPinned(ctx, pinned.ID, 1, true); err != nil {
+		t.Fatalf("UpdateChatPinned: %v", err)
+	}
+	if err := repos.chats.UpdateChatArchived(ctx, archived.ID, 1, true); err != nil {
+		t.Fatalf("UpdateChatArchived: %v", err)
+	}
+
+	summaries, err := repos.chats.GetAllChatSummeryByUserID(ctx, 1, false)
+	if err != nil {
+		t.Fatalf("GetAllChatSummeryByUserID: %v", err)
+	}
+	assertChatIDs(t, summaries, pinned.ID, newer.ID, older.ID)
+	if summaries[0].LastMessage != "second" {
+		t.Errorf("LastMessage = %q, want %q", summaries[0].LastMessage, "second")
+	}
+
+	summaries, err = repos.chats.GetAllChatSummeryByUserID(ctx, 1, true)
+	if err != nil {
+		t.Fatalf("GetAllChatSummeryByUserID: %v", err)
+	}
+	assertChatIDs(t, summaries, pinned.ID, archived.ID, newer.ID, older.ID)
+}
+
+func testPagination(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	chat := createChat(t, repos, 1, "chat", "m1")
+	ids := []uint{chat.Messages[0].ID}
+	for _, body := range []string{"m2", "m3", "m4", "m5"} {
+		ids = append(ids, createMessage(t, repos, chat.ID, 1, body).ID)
+	}
+
+	page, err := repos.messages.FindMessagesByChatIDPaginated(ctx, chat.ID, 0, 2)
+	if err != nil {
+		t.Fatalf("FindMessagesByChatIDPaginated: %v", err)
+	}
+	assertMessageIDs(t, page, ids[4], ids[3])
+
+	page, err = repos.messages.FindMessagesByChatIDPaginated(ctx, chat.ID, page[1].ID, 2)
+	if err != nil {
+		t.Fatalf("FindMessagesByChatIDPaginated: %v", err)
+	}
+	assertMessageIDs(t, page, ids[2], ids[1])
+
+	page, err = repos.messages.FindMessagesByChatIDPaginated(ctx, chat.ID, page[1].ID, 2)
+	if err != nil {
+		t.Fatalf("FindMessagesByChatIDPaginated: %v", err)
+	}
+	assertMessageIDs(t, page, ids[0])
+
+	since, err := repos.messages.FindMessagesSince(ctx, chat.ID, ids[1], 2)
+	if err != nil {
+		t.Fatalf("FindMessagesSince: %v", err)
+	}
+	assertMessageIDs(t, since, ids[3], ids[4])
+}
+
+func testNotFound(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	chat := createChat(t, repos, 1, "chat", "hello")
+	missing := chat.ID + 100
+
+	if _, err := repos.chats.FindChatByID(ctx, missing, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindChatByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repos.chats.GetChatSummary(ctx, missing, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetChatSummary: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := repos.chats.UpdateChatName(ctx, missing, 1, "name", model.ChatNameFromUser); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateChatName: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repos.messages.FindMessageByID(ctx, chat.Messages[0].ID+100, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindMessageByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	// A generated title never replaces one the user chose.
+	if err := repos.chats.UpdateChatName(ctx, chat.ID, 1, "mine", model.ChatNameFromUser); err != nil {
+		t.Fatalf("UpdateChatName: %v", err)
+	}
+	if err := repos.chats.UpdateChatName(ctx, chat.ID, 1, "generated", model.ChatNameGenerated); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateChatName over a user name: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	// A summary moved on by another answer is not overwritten.
+	if err := repos.chats.UpdateContextSummary(ctx, chat.ID, 1, 0, chat.Messages[0].ID, "summary"); err != nil {
+		t.Fatalf("UpdateContextSummary: %v", err)
+	}
+	if err := repos.chats.UpdateContextSummary(ctx, chat.ID, 1, 0, chat.Messages[0].ID, "stale"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("stale UpdateContextSummary: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func testUserScoping(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	chat := createChat(t, repos, 1, "mine", "the sabbath rest")
+	messageID := chat.Messages[0].ID
+
+	if _, err := repos.chats.FindChatByID(ctx, chat.ID, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindChatByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repos.chats.GetChatSummary(ctx, chat.ID, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetChatSummary: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repos.messages.FindMessageByID(ctx, messageID, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindMessageByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := repos.chats.UpdateChatPinned(ctx, chat.ID, 2, true); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateChatPinned: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := repos.chats.UpdateContextSummary(ctx, chat.ID, 2, 0, messageID, "summary"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateContextSummary: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	summaries, err := repos.chats.GetAllChatSummeryByUserID(ctx, 2, true)
+	if err != nil {
+		t.Fatalf("GetAllChatSummeryByUserID: %v", err)
+	}
+	assertChatIDs(t, summaries)
+
+	hits, err := repos.messages.SearchMessages(ctx, 2, "sabbath", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Errorf("SearchMessages found %d hits in another user's chats", len(hits))
+	}
+	hits, err = repos.messages.SearchMessages(ctx, 1, "sabbath", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(hits) != 1 || hits[0].MessageID != messageID {
+		t.Errorf("SearchMessages = %+v, want message %d", hits, messageID)
+	}
+
+	if err := repos.chats.DeleteChatByID(ctx, chat.ID, 2); err != nil {
+		t.Fatalf("DeleteChatByID: %v", err)
+	}
+	if err := repos.chats.DeleteAllChatsByUID(ctx, 2); err != nil {
+		t.Fatalf("DeleteAllChatsByUID: %v", err)
+	}
+	if _, err := repos.chats.FindChatByID(ctx, chat.ID, 1); err != nil {
+		t.Errorf("chat deleted by another user: %v", err)
+	}
+}
+
+func createChat(t *testing.T, repos repositories, userID uint, name, body string) *model.Chat {
+	t.Helper()
+	chat := &model.Chat{
+		UserID:   userID,
+		Name:     name,
+		Messages: []model.Message{{UserID: userID, Sender: "user", Body: body, Status: model.MessageStatusComplete}},
+	}
+	if err := repos.chats.CreateNewChat(context.Background(), chat); err != nil {
+		t.Fatalf("CreateNewChat: %v", err)
+	}
+	return chat
+}
+
+func createMessage(t *testing.T, repos repositories, chatID, userID uint, body string) *model.Message {
+	t.Helper()
+	message := &model.Message{ChatID: chatID, UserID: userID, Sender: "user", Body: body, Status: model.MessageStatusComplete}
+	if err := repos.messages.CreateNewMessage(context.Background(), message); err != nil {
+		t.Fatalf("CreateNewMessage: %v", err)
+	}
+	return message
+}
+
+func assertChatIDs(t *testing.T, chats []model.ChatSummery, want ...uint) {
+	t.Helper()
+	got := make([]uint, len(chats))
+	for i, chat := range chats {
+		got[i] = chat.ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("chat IDs = %v, want %v", got, want)
+	}
+}
+
+func assertMessageIDs(t *testing.T, messages []model.Message, want ...uint) {
+	t.Helper()
+	got := make([]uint, len(messages))
+	for i, message := range messages {
+		got[i] = message.ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("message IDs = %v, want %v", got, want)
+	}
+}
+
+// openTestDB migrates a fresh schema in the Postgres database named by
+// TEST_DATABASE_DSN and drops it when the test ends. The test is skipped
+// when the variable is unset.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), gormConfig)
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrate.New(sqlDB, migrations.FS, "chat-service-test")
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// withSearchPath points every connection opened with dsn at schema. It
+// takes both URL and key=value DSNs.
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
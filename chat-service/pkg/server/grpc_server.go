@@ -2,29 +2,33 @@ package server
 
 import (
 	contextkeys "chat-service/pkg/context-keys"
+	"chat-service/pkg/model"
 	"chat-service/pkg/proto"
 	"chat-service/pkg/service"
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
 )
 
 type ChatServer struct {
 	chatService    *service.ChatService
 	messageService *service.MessageService
-	openAIService  *service.OpenAIService
+	llmService     *service.LLMService
 	proto.UnimplementedChatServiceServer
 }
 
-func NewChatServer(chatService *service.ChatService, messageService *service.MessageService, openAIService *service.OpenAIService) *ChatServer {
+func NewChatServer(chatService *service.ChatService, messageService *service.MessageService, llmService *service.LLMService) *ChatServer {
 	return &ChatServer{
 		chatService:    chatService,
 		messageService: messageService,
-		openAIService:  openAIService,
+		llmService:     llmService,
 	}
 }
 
@@ -34,9 +38,12 @@ func (s *ChatServer) CreateMessage(ctx context.Context, req *proto.CreateMessage
 	body := req.GetBody()
 	userID := ctx.Value(contextkeys.Userkey).(uint)
 
-	messsage, err := s.messageService.CreateMessage(chatID, sender, body, userID)
+	messsage, err := s.messageService.CreateMessage(ctx, chatID, sender, body, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "chat not found")
+	}
 	if err != nil {
-		log.Printf("Failed to create message: %v", err)
+		slog.ErrorContext(ctx, "Failed to create message", "err", err)
 		return nil, err
 	}
 
@@ -58,9 +65,9 @@ func (s *ChatServer) CreateChat(ctx context.Context, req *proto.CreateChatReques
 	sender := req.GetSender()
 	body := req.GetBody()
 
-	chat, message, err := s.messageService.CreateInitialMessage(userID, sender, body)
+	chat, message, err := s.messageService.CreateInitialMessage(ctx, userID, sender, body)
 	if err != nil {
-		log.Printf("Failed to create message and chat: %v", err)
+		slog.ErrorContext(ctx, "Failed to create message and chat", "err", err)
 		return nil, err
 	}
 
@@ -82,16 +89,17 @@ func (s *ChatServer) CreateChat(ctx context.Context, req *proto.CreateChatReques
 }
 
 func (s *ChatServer) GetRecentMessages(ctx context.Context, req *proto.GetRecentMessagesRequest) (*proto.GetRecentMessagesResponse, error) {
-	fmt.Printf("get recent messages called with: %v\n", req)
 	chatID := uint(req.GetChatId())
 	lastMessageID := uint(req.GetLastMessageId())
 	limit := uint(req.GetLimit())
-	if limit == 0 {
-		limit = 5
+	userID := ctx.Value(contextkeys.Userkey).(uint)
+
+	messages, err := s.messageService.FindMessagesByChatIDPaginated(ctx, chatID, lastMessageID, limit, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "chat not found")
 	}
-	messages, err := s.messageService.FindMessagesByChatIDPaginated(chatID, lastMessageID, limit)
 	if err != nil {
-		log.Printf("Failed to find messages: %v\n", err)
+		slog.ErrorContext(ctx, "Failed to find messages", "err", err)
 		return nil, err
 	}
 
@@ -103,6 +111,7 @@ func (s *ChatServer) GetRecentMessages(ctx context.Context, req *proto.GetRecent
 			Sender:    msg.Sender,
 			Body:      msg.Body,
 			CreatedAt: timestamppb.New(msg.CreatedAt),
+			Status:    msg.Status,
 		}
 		msgs = append(msgs, protoMsg)
 	}
@@ -110,53 +119,82 @@ func (s *ChatServer) GetRecentMessages(ctx context.Context, req *proto.GetRecent
 	resp := &proto.GetRecentMessagesResponse{
 		Messages: msgs,
 	}
-	fmt.Printf("returning recent messages response: %v\n", resp)
 	return resp, nil
 }
 
 func (s *ChatServer) StreamMessages(stream proto.ChatService_StreamMessagesServer) error {
 	req, err := stream.Recv()
 	if err != nil {
-		log.Printf("Error receiving message from client: %v", err)
+		slog.ErrorContext(stream.Context(), "Error receiving message from client", "err", err)
 		return err
 	}
-	userID := stream.Context().Value(contextkeys.Userkey).(uint)
+	ctx := stream.Context()
+	userID := ctx.Value(contextkeys.Userkey).(uint)
 	sender := req.GetSender()
 	body := req.GetBody()
 	var chatID uint
 	if req.GetChatId() == 0 {
 
-		chat, _, createErr := s.messageService.CreateInitialMessage(userID, sender, body)
+		chat, _, createErr := s.messageService.CreateInitialMessage(ctx, userID, sender, body)
 		if createErr != nil {
-			log.Printf("Failed to create initial message and chat: %v", createErr)
+			slog.ErrorContext(ctx, "Failed to create initial message and chat", "err", createErr)
 			return createErr
 		}
 		chatID = chat.ID
 	} else {
 		chatID = uint(req.GetChatId())
-		_, err := s.messageService.CreateMessage(chatID, sender, body, userID)
+		_, err := s.messageService.CreateMessage(ctx, chatID, sender, body, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return status.Error(codes.NotFound, "chat not found")
+		}
 		if err != nil {
-			log.Printf("Failed to store user message: %v", err)
+			slog.ErrorContext(ctx, "Failed to store user message", "err", err)
+			return status.Error(codes.Internal, "failed to store message")
+		}
+	}
+
+	answer, err := s.handleLLMResponse(chatID, "Bible Dive", body, userID, stream)
+	if err != nil {
+		return err
+	}
+	s.titleChat(ctx, chatID, userID, body, answer, stream)
+	return nil
+}
+
+func (s *ChatServer) GetMessageByID(ctx context.Context, req *proto.GetMessageByIDRequest) (*proto.GetMessageByIDResponse, error) {
+	userID := ctx.Value(contextkeys.Userkey).(uint)
+	message, err := s.messageService.FindMessageByID(ctx, uint(req.GetMessageId()), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "message not found")
 		}
+		slog.ErrorContext(ctx, "Failed to find message", "err", err)
+		return nil, status.Error(codes.Internal, "failed to find message")
 	}
 
-	return s.handleOpenAIResponse(chatID, "Bible Dive", body, userID, stream)
+	return &proto.GetMessageByIDResponse{
+		Message: &proto.Message{
+			Id:        uint32(message.ID),
+			ChatId:    uint32(message.ChatID),
+			Sender:    message.Sender,
+			Body:      message.Body,
+			CreatedAt: timestamppb.New(message.CreatedAt),
+			Status:    message.Status,
+		},
+	}, nil
 }
 
 func (s *ChatServer) GetChatSummariesUID(ctx context.Context, req *proto.GetChatSummariesUIDRequest) (*proto.GetChatSummariesUIDResponse, error) {
 	userID := ctx.Value(contextkeys.Userkey).(uint)
-	chats, err := s.chatService.GetAllChatSummeryByUID(userID)
+	chats, err := s.chatService.GetAllChatSummeryByUID(ctx, userID, req.GetIncludeArchived())
 	if err != nil {
-		log.Printf("Failed to get all chats")
+		slog.ErrorContext(ctx, "Failed to get all chats", "err", err)
 		return nil, err
 	}
 
 	var protoChatSummeries []*proto.ChatSummary
 	for _, summary := range chats {
-		protoChatSummeries = append(protoChatSummeries, &proto.ChatSummary{
-			Id:   uint32(summary.ID),
-			Name: summary.Name,
-		})
+		protoChatSummeries = append(protoChatSummeries, toProtoChatSummary(&summary))
 	}
 
 	resp := &proto.GetChatSummariesUIDResponse{
@@ -166,12 +204,39 @@ func (s *ChatServer) GetChatSummariesUID(ctx context.Context, req *proto.GetChat
 	return resp, nil
 }
 
+func (s *ChatServer) RenameChat(ctx context.Context, req *proto.RenameChatRequest) (*proto.RenameChatResponse, error) {
+	userID := ctx.Value(contextkeys.Userkey).(uint)
+	chat, err := s.chatService.RenameChat(ctx, uint(req.GetChatId()), userID, req.GetName())
+	if err != nil {
+		return nil, chatUpdateError(ctx, "rename", err)
+	}
+	return &proto.RenameChatResponse{Chat: toProtoChatSummary(chat)}, nil
+}
+
+func (s *ChatServer) PinChat(ctx context.Context, req *proto.PinChatRequest) (*proto.PinChatResponse, error) {
+	userID := ctx.Value(contextkeys.Userkey).(uint)
+	chat, err := s.chatService.PinChat(ctx, uint(req.GetChatId()), userID, req.GetPinned())
+	if err != nil {
+		return nil, chatUpdateError(ctx, "pin", err)
+	}
+	return &proto.PinChatResponse{Chat: toProtoChatSummary(chat)}, nil
+}
+
+func (s *ChatServer) ArchiveChat(ctx context.Context, req *proto.ArchiveChatRequest) (*proto.ArchiveChatResponse, error) {
+	userID := ctx.Value(contextkeys.Userkey).(uint)
+	chat, err := s.chatService.ArchiveChat(ctx, uint(req.GetChatId()), userID, req.GetArchived())
+	if err != nil {
+		return nil, chatUpdateError(ctx, "archive", err)
+	}
+	return &proto.ArchiveChatResponse{Chat: toProtoChatSummary(chat)}, nil
+}
+
 func (s *ChatServer) DeleteChatByID(ctx context.Context, req *proto.DeleteChatByIDRequest) (*proto.DeleteChatByIDResponse, error) {
 	userID := uint(req.GetUserId())
 	chatID := uint(req.GetChatId())
 	err := s.chatService.DeleteChatByID(ctx, chatID, userID)
 	if err != nil {
-		log.Printf("Failed to delete chat: %v", err)
+		slog.ErrorContext(ctx, "Failed to delete chat", "err", err)
 		return &proto.DeleteChatByIDResponse{
 			Success: false,
 		}, status.Error(codes.Internal, "failed to delete chat")
@@ -186,7 +251,7 @@ func (s *ChatServer) DeleteAllChatsByUID(ctx context.Context, req *proto.DeleteA
 
 	err := s.chatService.DeleteAllChatsByUID(ctx, userID)
 	if err != nil {
-		log.Printf("Failed to delete chat: %v", err)
+		slog.ErrorContext(ctx, "Failed to delete chat", "err", err)
 		return &proto.DeleteAllChatsByUIDResponse{
 			Success: false,
 		}, status.Error(codes.Internal, "failed to delete chat")
@@ -196,9 +261,71 @@ func (s *ChatServer) DeleteAllChatsByUID(ctx context.Context, req *proto.DeleteA
 	}, nil
 }
 
+func (s *ChatServer) SearchMessages(ctx context.Context, req *proto.SearchMessagesRequest) (*proto.SearchMessagesResponse, error) {
+	userID := ctx.Value(contextkeys.Userkey).(uint)
+	hits, err := s.messageService.SearchMessages(ctx, userID, req.GetQuery(), uint(req.GetLimit()), uint(req.GetOffset()))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		slog.ErrorContext(ctx, "Failed to search messages", "err", err)
+		return nil, status.Error(codes.Internal, "failed to search messages")
+	}
+
+	resp := &proto.SearchMessagesResponse{}
+	for _, hit := range hits {
+		resp.Hits = append(resp.Hits, &proto.SearchHit{
+			ChatId:    uint32(hit.ChatID),
+			ChatName:  hit.ChatName,
+			MessageId: uint32(hit.MessageID),
+			Sender:    hit.Sender,
+			Snippet:   hit.Snippet,
+			CreatedAt: timestamppb.New(hit.CreatedAt),
+			Rank:      hit.Rank,
+		})
+	}
+	return resp, nil
+}
+
 // helper functions
-func (s *ChatServer) handleOpenAIResponse(chatID uint, sender string, prompt string, userID uint, stream proto.ChatService_StreamMessagesServer) error {
-	return s.openAIService.SendMessageToOpenAI(context.Background(), chatID, prompt, userID, func(response string, messageID uint) error {
+
+// previewLength bounds the last message shown in a chat summary, in
+// characters.
+const previewLength = 120
+
+func toProtoChatSummary(chat *model.ChatSummery) *proto.ChatSummary {
+	preview := []rune(strings.Join(strings.Fields(chat.LastMessage), " "))
+	if len(preview) > previewLength {
+		preview = append(preview[:previewLength-1], '…')
+	}
+	return &proto.ChatSummary{
+		Id:                 uint32(chat.ID),
+		Name:               chat.Name,
+		Pinned:             chat.Pinned,
+		Archived:           chat.Archived,
+		UpdatedAt:          timestamppb.New(chat.UpdatedAt),
+		LastMessagePreview: string(preview),
+	}
+}
+
+// chatUpdateError maps a failed rename, pin or archive to a status. Chats
+// of other users are reported as missing.
+func chatUpdateError(ctx context.Context, action string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Error(codes.NotFound, "chat not found")
+	case errors.Is(err, service.ErrInvalidChatName):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	slog.ErrorContext(ctx, "Failed to update chat", "action", action, "err", err)
+	return status.Errorf(codes.Internal, "failed to %s chat", action)
+}
+
+// handleLLMResponse relays the answer to the client and returns it.
+func (s *ChatServer) handleLLMResponse(chatID uint, sender string, prompt string, userID uint, stream proto.ChatService_StreamMessagesServer) (string, error) {
+	var answer strings.Builder
+	err := s.llmService.SendMessage(stream.Context(), chatID, prompt, userID, func(response string, messageID uint) error {
+		answer.WriteString(response)
 		resp := &proto.CreateMessageResponse{
 			Message: &proto.Message{
 				Id:     uint32(messageID),
@@ -208,9 +335,48 @@ func (s *ChatServer) handleOpenAIResponse(chatID uint, sender string, prompt str
 			},
 		}
 		if sendErr := stream.Send(resp); sendErr != nil {
-			log.Printf("Error sending message to client: %v", sendErr)
+			slog.ErrorContext(stream.Context(), "Error sending message to client", "err", sendErr)
 			return sendErr
 		}
 		return nil
 	})
+	return answer.String(), err
+}
+
+// titleTimeout bounds title generation, which holds the stream open after
+// the answer is complete.
+const titleTimeout = 15 * time.Second
+
+// titleChat replaces a name taken from the first prompt with a generated
+// title once the chat has an answer, and pushes the new name on the
+// stream. Failures only leave the old name in place, to be retried after
+// the next answer.
+func (s *ChatServer) titleChat(ctx context.Context, chatID, userID uint, prompt, answer string, stream proto.ChatService_StreamMessagesServer) {
+	if answer == "" {
+		return
+	}
+	needsTitle, err := s.chatService.NeedsTitle(ctx, chatID, userID)
+	if err != nil || !needsTitle {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, titleTimeout)
+	defer cancel()
+	title, err := s.llmService.GenerateTitle(ctx, prompt, answer)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to generate chat title", "chat_id", chatID, "err", err)
+		return
+	}
+	renamed, err := s.chatService.SetGeneratedTitle(ctx, chatID, userID, title)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to save chat title", "chat_id", chatID, "err", err)
+		return
+	}
+	if !renamed {
+		return
+	}
+
+	if err := stream.Send(&proto.CreateMessageResponse{RenamedChat: &proto.Chat{Id: uint32(chatID), Name: title}}); err != nil {
+		slog.WarnContext(ctx, "Failed to send chat title", "chat_id", chatID, "err", err)
+	}
 }
@@ -0,0 +1,236 @@
+package server_test
+
+import (
+	"chat-service/pkg/interceptors"
+	"chat-service/pkg/llm"
+	"chat-service/pkg/model"
+	"chat-service/pkg/proto"
+	"chat-service/pkg/repository"
+	"chat-service/pkg/server"
+	"chat-service/pkg/service"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "test-secret"
+
+// fakeProvider streams its answer in words, then fails with streamErr if
+// it is set. Completions, used for titles and summaries, return title.
+type fakeProvider struct {
+	answer    string
+	streamErr error
+	title     string
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, req llm.Request) (llm.Response, error) {
+	return llm.Response{Content: p.title}, nil
+}
+
+func (p *fakeProvider) Stream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	return &fakeStream{chunks: strings.SplitAfter(p.answer, " "), err: p.streamErr}, nil
+}
+
+func (p *fakeProvider) CompleteStructured(ctx context.Context, req llm.Request, fn llm.Function) (string, llm.Usage, error) {
+	return "", llm.Usage{}, errors.New("not supported")
+}
+
+type fakeStream struct {
+	chunks []string
+	err    error
+}
+
+func (s *fakeStream) Recv() (llm.Chunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return llm.Chunk{}, s.err
+		}
+		return llm.Chunk{}, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return llm.Chunk{Content: chunk}, nil
+}
+
+func (s *fakeStream) Close() error { return nil }
+
+// newTestClient serves a ChatServer backed by the in-memory repositories
+// over bufconn, behind the same auth interceptors as in production.
+func newTestClient(t *testing.T, provider llm.Provider) proto.ChatServiceClient {
+	t.Helper()
+	db := repository.NewMemoryDB()
+	chatRepo, msgRepo := db.ChatRepository(), db.MessageRepository()
+	fakeModel := llm.NewModel(provider, "fake")
+
+	chatService := service.NewChatService(chatRepo)
+	msgService := service.NewMessageService(msgRepo, chatRepo)
+	contextBuilder := service.NewContextBuilder(fakeModel, 8000, chatRepo, msgRepo)
+	llmService := service.NewLLMService(fakeModel, fakeModel, contextBuilder, msgService)
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptors.NewUnaryInterceptor(testSecret)),
+		grpc.StreamInterceptor(interceptors.NewStreamInterceptor(testSecret)),
+	)
+	proto.RegisterChatServiceServer(grpcServer, server.NewChatServer(chatService, msgService, llmService))
+
+	lis := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return proto.NewChatServiceClient(conn)
+}
+
+// asUser returns a context carrying an access token for userID, as the
+// gateway sends it.
+func asUser(t *testing.T, userID uint) context.Context {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// streamMessage sends one prompt and collects the answer and any rename
+// until the server closes the stream.
+func streamMessage(ctx context.Context, client proto.ChatServiceClient, chatID uint32, body string) (answer string, messageID uint32, renamed *proto.Chat, err error) {
+	stream, err := client.StreamMessages(ctx)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if err := stream.Send(&proto.CreateMessageRequest{ChatId: chatID, Sender: "user", Body: body}); err != nil {
+		return "", 0, nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return "", 0, nil, err
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return answer, messageID, renamed, nil
+		}
+		if err != nil {
+			return answer, messageID, renamed, err
+		}
+		if resp.GetRenamedChat() != nil {
+			renamed = resp.GetRenamedChat()
+			continue
+		}
+		answer += resp.GetMessage().GetBody()
+		messageID = resp.GetMessage().GetId()
+	}
+}
+
+func TestStreamMessagesAnswersAndTitlesNewChat(t *testing.T) {
+	client := newTestClient(t, &fakeProvider{answer: "Remember the Sabbath day, to keep it holy.", title: "Keeping the Sabbath"})
+	ctx := asUser(t, 1)
+
+	answer, messageID, renamed, err := streamMessage(ctx, client, 0, "What does Exodus 20:8 say?")
+	if err != nil {
+		t.Fatalf("StreamMessages: %v", err)
+	}
+	if answer != "Remember the Sabbath day, to keep it holy." {
+		t.Errorf("answer = %q", answer)
+	}
+	if renamed == nil || renamed.GetName() != "Keeping the Sabbath" {
+		t.Fatalf("renamed chat = %v, want the generated title", renamed)
+	}
+
+	message, err := client.GetMessageByID(ctx, &proto.GetMessageByIDRequest{MessageId: messageID})
+	if err != nil {
+		t.Fatalf("GetMessageByID: %v", err)
+	}
+	if message.GetMessage().GetBody() != answer || message.GetMessage().GetStatus() != model.MessageStatusComplete {
+		t.Errorf("stored answer = %q (%s), want %q (complete)", message.GetMessage().GetBody(), message.GetMessage().GetStatus(), answer)
+	}
+
+	chats, err := client.GetChatSummariesUID(ctx, &proto.GetChatSummariesUIDRequest{})
+	if err != nil {
+		t.Fatalf("GetChatSummariesUID: %v", err)
+	}
+	if len(chats.GetChats()) != 1 || chats.GetChats()[0].GetId() != renamed.GetId() || chats.GetChats()[0].GetName() != "Keeping the Sabbath" {
+		t.Errorf("chats = %v, want the titled chat", chats.GetChats())
+	}
+}
+
+func TestStreamMessagesMarksFailedAnswers(t *testing.T) {
+	client := newTestClient(t, &fakeProvider{answer: "Partial answer", streamErr: errors.New("connection reset")})
+	ctx := asUser(t, 1)
+
+	_, messageID, _, err := streamMessage(ctx, client, 0, "Who wrote Hebrews?")
+	if err == nil {
+		t.Fatal("StreamMessages succeeded, want the stream error")
+	}
+	message, err := client.GetMessageByID(ctx, &proto.GetMessageByIDRequest{MessageId: messageID})
+	if err != nil {
+		t.Fatalf("GetMessageByID: %v", err)
+	}
+	if got := message.GetMessage().GetStatus(); got != model.MessageStatusFailed {
+		t.Errorf("status = %q, want %q", got, model.MessageStatusFailed)
+	}
+}
+
+func TestChatsAreScopedToTheirUser(t *testing.T) {
+	client := newTestClient(t, &fakeProvider{answer: "Amen.", title: "Title"})
+	owner, other := asUser(t, 1), asUser(t, 2)
+
+	created, err := client.CreateChat(owner, &proto.CreateChatRequest{Sender: "user", Body: "Psalm 23"})
+	if err != nil {
+		t.Fatalf("CreateChat: %v", err)
+	}
+	chatID := created.GetChat().GetId()
+
+	recent, err := client.GetRecentMessages(owner, &proto.GetRecentMessagesRequest{ChatId: chatID})
+	if err != nil || len(recent.GetMessages()) != 1 {
+		t.Errorf("GetRecentMessages of the owner's chat = %v, %v, want the first message", recent.GetMessages(), err)
+	}
+
+	_, _, _, err = streamMessage(other, client, chatID, "Let me in")
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("StreamMessages into another user's chat: err = %v, want NotFound", err)
+	}
+	_, err = client.CreateMessage(other, &proto.CreateMessageRequest{ChatId: chatID, Sender: "user", Body: "Let me in"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("CreateMessage into another user's chat: err = %v, want NotFound", err)
+	}
+	_, err = client.GetMessageByID(other, &proto.GetMessageByIDRequest{MessageId: created.GetMessage().GetId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetMessageByID of another user's message: err = %v, want NotFound", err)
+	}
+	_, err = client.GetRecentMessages(other, &proto.GetRecentMessagesRequest{ChatId: chatID})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetRecentMessages of another user's chat: err = %v, want NotFound", err)
+	}
+	_, err = client.RenameChat(other, &proto.RenameChatRequest{ChatId: chatID, Name: "Mine now"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("RenameChat of another user's chat: err = %v, want NotFound", err)
+	}
+}
+
+func TestCallsWithoutTokenAreRejected(t *testing.T) {
+	client := newTestClient(t, &fakeProvider{})
+
+	_, err := client.GetChatSummariesUID(context.Background(), &proto.GetChatSummariesUIDRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("err = %v, want Unauthenticated", err)
+	}
+}
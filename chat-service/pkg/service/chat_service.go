@@ -7,34 +7,73 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
 )
 
 type ChatService struct {
-	chatRepository *repository.ChatRepository
+	chatRepository repository.ChatRepository
 }
 
-func NewChatService(chatRepo *repository.ChatRepository) *ChatService {
+func NewChatService(chatRepo repository.ChatRepository) *ChatService {
 	return &ChatService{
 		chatRepository: chatRepo,
 	}
 }
 
-func (s *ChatService) UpdateChatName(chatID uint, name string) (*model.Chat, error) {
-	chat, err := s.chatRepository.FindChatByID(chatID)
-	if err != nil {
+// maxChatNameLength bounds chat names, in characters.
+const maxChatNameLength = 100
+
+var ErrInvalidChatName = errors.New("chat name must be between 1 and 100 characters")
+
+func (s *ChatService) RenameChat(ctx context.Context, chatID, userID uint, name string) (*model.ChatSummery, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxChatNameLength {
+		return nil, ErrInvalidChatName
+	}
+	if err := s.chatRepository.UpdateChatName(ctx, chatID, userID, name, model.ChatNameFromUser); err != nil {
 		return nil, err
 	}
+	return s.chatRepository.GetChatSummary(ctx, chatID, userID)
+}
+
+// NeedsTitle reports whether the chat is still named after its first
+// prompt, so a generated title may replace the name.
+func (s *ChatService) NeedsTitle(ctx context.Context, chatID, userID uint) (bool, error) {
+	chat, err := s.chatRepository.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return false, err
+	}
+	return chat.NameSource == model.ChatNameFromPrompt, nil
+}
+
+// SetGeneratedTitle names the chat with a generated title. It reports false,
+// leaving the chat alone, when the user renamed the chat in the meantime.
+func (s *ChatService) SetGeneratedTitle(ctx context.Context, chatID, userID uint, title string) (bool, error) {
+	err := s.chatRepository.UpdateChatName(ctx, chatID, userID, title, model.ChatNameGenerated)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
 
-	chat.Name = strings.TrimSpace(name)
+func (s *ChatService) PinChat(ctx context.Context, chatID, userID uint, pinned bool) (*model.ChatSummery, error) {
+	if err := s.chatRepository.UpdateChatPinned(ctx, chatID, userID, pinned); err != nil {
+		return nil, err
+	}
+	return s.chatRepository.GetChatSummary(ctx, chatID, userID)
+}
 
-	if err := s.chatRepository.UpdateChat(chat); err != nil {
+func (s *ChatService) ArchiveChat(ctx context.Context, chatID, userID uint, archived bool) (*model.ChatSummery, error) {
+	if err := s.chatRepository.UpdateChatArchived(ctx, chatID, userID, archived); err != nil {
 		return nil, err
 	}
-	return chat, nil
+	return s.chatRepository.GetChatSummary(ctx, chatID, userID)
 }
 
-func (s *ChatService) GetAllChatSummeryByUID(userID uint) ([]model.ChatSummery, error) {
-	return s.chatRepository.GetAllChatSummeryByUserID(userID)
+func (s *ChatService) GetAllChatSummeryByUID(ctx context.Context, userID uint, includeArchived bool) ([]model.ChatSummery, error) {
+	return s.chatRepository.GetAllChatSummeryByUserID(ctx, userID, includeArchived)
 }
 
 func (s *ChatService) DeleteChatByID(ctx context.Context, chatID, userID uint) error {
@@ -42,7 +81,7 @@ func (s *ChatService) DeleteChatByID(ctx context.Context, chatID, userID uint) e
 	if !ok || ctxUID != userID {
 		return errors.New("unauthorized: user ID invalid")
 	}
-	return s.chatRepository.DeleteChatByID(chatID, userID)
+	return s.chatRepository.DeleteChatByID(ctx, chatID, userID)
 }
 
 func (s *ChatService) DeleteAllChatsByUID(ctx context.Context, userID uint) error {
@@ -50,7 +89,7 @@ func (s *ChatService) DeleteAllChatsByUID(ctx context.Context, userID uint) erro
 	if !ok || ctxUID != userID {
 		return errors.New("unauthorized: user ID invalid")
 	}
-	return s.chatRepository.DeleteAllChatsByUID(userID)
+	return s.chatRepository.DeleteAllChatsByUID(ctx, userID)
 }
 
 // helper funcs
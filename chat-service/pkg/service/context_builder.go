@@ -0,0 +1,198 @@
+package service
+
+import (
+	"chat-service/pkg/llm"
+	"chat-service/pkg/model"
+	"chat-service/pkg/repository"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxHistoryMessages bounds the unsummarized history read per answer.
+	maxHistoryMessages = 200
+
+	// summaryMaxTokens bounds the running summary, which is kept in the
+	// budget next to the history.
+	summaryMaxTokens = 500
+)
+
+const summaryPrompt = "You keep the running summary of a Bible study conversation between a user and an assistant. Update the summary with the new turns. Keep the questions the user asked, the passages discussed with their references, the conclusions reached and any open threads, so the conversation can continue from the summary alone. Answer with the updated summary only, in under 250 words."
+
+// ContextBuilder assembles the messages sent to the chat model: the system
+// prompt, the chat's running summary, as much recent history as fits the
+// token budget in chronological order, and the prompt. History that no
+// longer fits is folded into the summary, half a budget at a time so a
+// long chat is summarized every few turns rather than on every one.
+type ContextBuilder struct {
+	summaryModel      llm.Model
+	budget            int
+	chatRepository    repository.ChatRepository
+	messageRepository repository.MessageRepository
+}
+
+// NewContextBuilder returns a builder that keeps prompts within budget
+// tokens and folds history with summaryModel.
+func NewContextBuilder(summaryModel llm.Model, budget int, chatRepo repository.ChatRepository, msgRepo repository.MessageRepository) *ContextBuilder {
+	return &ContextBuilder{
+		summaryModel:      summaryModel,
+		budget:            budget,
+		chatRepository:    chatRepo,
+		messageRepository: msgRepo,
+	}
+}
+
+// Build assembles the context for answering prompt in one of the user's
+// chats.
+func (b *ContextBuilder) Build(ctx context.Context, chatID, userID uint, system, prompt string) ([]llm.Message, error) {
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: system}}
+	question := llm.Message{Role: llm.RoleUser, Content: prompt}
+	if chatID == 0 {
+		return append(messages, question), nil
+	}
+
+	chat, err := b.chatRepository.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := b.messageRepository.FindMessagesSince(ctx, chatID, chat.ContextSummaryUntil, maxHistoryMessages)
+	if err != nil {
+		return nil, err
+	}
+	turns := historyTurns(history, prompt)
+
+	fixed := llm.MessageTokens(messages[0], question)
+	summary := chat.ContextSummary
+	if summary != "" {
+		fixed += llm.MessageTokens(summaryMessage(summary))
+	}
+	kept := newestWithin(turns, b.budget-fixed)
+	if kept < len(turns) {
+		// Fold down to half of what is left next to a full summary, and
+		// never keep an answer without its question.
+		room := b.budget - llm.MessageTokens(messages[0], question, summaryMessage("")) - summaryMaxTokens
+		keep := newestWithin(turns, room/2)
+		for keep > 0 && turns[len(turns)-keep].message.Role == llm.RoleAssistant {
+			keep--
+		}
+		folded, err := b.fold(ctx, chat, turns[:len(turns)-keep])
+		if err != nil {
+			// Answer with the old summary; the turns are folded next time.
+			slog.WarnContext(ctx, "Failed to summarize chat history", "chat_id", chatID, "err", err)
+		} else {
+			summary, kept = folded, keep
+		}
+	}
+
+	if summary != "" {
+		messages = append(messages, summaryMessage(summary))
+	}
+	for _, turn := range turns[len(turns)-kept:] {
+		messages = append(messages, turn.message)
+	}
+	return append(messages, question), nil
+}
+
+type turn struct {
+	id      uint
+	message llm.Message
+}
+
+// historyTurns converts stored messages to chat turns. The prompt has
+// already been stored as the newest message, so it is left out here and
+// sent last.
+func historyTurns(history []model.Message, prompt string) []turn {
+	if n := len(history); n > 0 && history[n-1].Sender != "AI" && history[n-1].Body == prompt {
+		history = history[:n-1]
+	}
+	turns := make([]turn, 0, len(history))
+	for _, msg := range history {
+		if strings.TrimSpace(msg.Body) == "" {
+			continue
+		}
+		role := llm.RoleUser
+		if msg.Sender == "AI" {
+			role = llm.RoleAssistant
+		}
+		turns = append(turns, turn{id: msg.ID, message: llm.Message{Role: role, Content: msg.Body}})
+	}
+	return turns
+}
+
+// newestWithin counts the newest turns that fit in budget tokens.
+func newestWithin(turns []turn, budget int) int {
+	n := 0
+	for i := len(turns) - 1; i >= 0; i-- {
+		budget -= llm.MessageTokens(turns[i].message)
+		if budget < 0 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func summaryMessage(summary string) llm.Message {
+	return llm.Message{Role: llm.RoleSystem, Content: "Summary of the earlier conversation:\n" + summary}
+}
+
+// fold summarizes turns into the chat's running summary and stores it. The
+// oldest turns are dropped if they would overflow the summary request.
+func (b *ContextBuilder) fold(ctx context.Context, chat *model.Chat, turns []turn) (string, error) {
+	if len(turns) == 0 {
+		return chat.ContextSummary, nil
+	}
+	until := turns[len(turns)-1].id
+
+	budget := b.budget - llm.EstimateTokens(summaryPrompt+chat.ContextSummary)
+	turns = turns[len(turns)-max(newestWithin(turns, budget), 1):]
+
+	var transcript strings.Builder
+	transcript.WriteString("Current summary:\n")
+	if chat.ContextSummary == "" {
+		transcript.WriteString("(none)")
+	} else {
+		transcript.WriteString(chat.ContextSummary)
+	}
+	transcript.WriteString("\n\nNew turns:\n")
+	for _, turn := range turns {
+		speaker := "User"
+		if turn.message.Role == llm.RoleAssistant {
+			speaker = "Assistant"
+		}
+		transcript.WriteString(speaker + ": " + turn.message.Content + "\n\n")
+	}
+
+	resp, err := b.summaryModel.Complete(ctx, llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: summaryPrompt},
+			{Role: llm.RoleUser, Content: transcript.String()},
+		},
+		MaxTokens:   summaryMaxTokens,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", errors.New("summary model returned an empty summary")
+	}
+
+	// The summary is worth keeping even if the answer is then cancelled.
+	err = b.chatRepository.UpdateContextSummary(context.WithoutCancel(ctx), chat.ID, chat.UserID, chat.ContextSummaryUntil, until, summary)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Another answer folded the history first; this one still uses its
+		// own summary.
+		return summary, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	slog.InfoContext(ctx, "Folded chat history into summary", "chat_id", chat.ID, "turns", len(turns), "until_message_id", until)
+	return summary, nil
+}
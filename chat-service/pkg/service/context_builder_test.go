@@ -0,0 +1,253 @@
+package service_test
+
+import (
+	"chat-service/pkg/llm"
+	"chat-service/pkg/model"
+	"chat-service/pkg/repository"
+	"chat-service/pkg/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	testBudget = 1000
+	testSystem = "You are a Bible study assistant."
+)
+
+// summaryProvider answers summary requests with summary, or fails with err.
+// It records every request and runs beforeReply first, if set.
+type summaryProvider struct {
+	summary     string
+	err         error
+	beforeReply func()
+	requests    []llm.Request
+}
+
+func (p *summaryProvider) Complete(ctx context.Context, req llm.Request) (llm.Response, error) {
+	p.requests = append(p.requests, req)
+	if p.beforeReply != nil {
+		p.beforeReply()
+	}
+	return llm.Response{Content: p.summary}, p.err
+}
+
+func (p *summaryProvider) Stream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *summaryProvider) CompleteStructured(ctx context.Context, req llm.Request, fn llm.Function) (string, llm.Usage, error) {
+	return "", llm.Usage{}, errors.New("not supported")
+}
+
+type contextFixture struct {
+	chats    repository.ChatRepository
+	messages repository.MessageRepository
+	builder  *service.ContextBuilder
+	chat     *model.Chat
+	stored   []model.Message
+}
+
+// newContextFixture stores a chat of user 1 with turns alternating between
+// the user and the AI, each about 29 tokens.
+func newContextFixture(t *testing.T, provider llm.Provider, turns int) *contextFixture {
+	t.Helper()
+	db := repository.NewMemoryDB()
+	f := &contextFixture{chats: db.ChatRepository(), messages: db.MessageRepository()}
+	f.builder = service.NewContextBuilder(llm.NewModel(provider, "fake"), testBudget, f.chats, f.messages)
+
+	f.chat = &model.Chat{UserID: 1, Name: "Romans"}
+	if err := f.chats.CreateNewChat(context.Background(), f.chat); err != nil {
+		t.Fatalf("CreateNewChat: %v", err)
+	}
+	for i := 0; i < turns; i++ {
+		sender := "user"
+		if i%2 == 1 {
+			sender = "AI"
+		}
+		f.add(t, sender, fmt.Sprintf("turn %02d %s", i, strings.Repeat("word ", 18)))
+	}
+	return f
+}
+
+func (f *contextFixture) add(t *testing.T, sender, body string) {
+	t.Helper()
+	message := &model.Message{ChatID: f.chat.ID, UserID: 1, Sender: sender, Body: body}
+	if err := f.messages.CreateNewMessage(context.Background(), message); err != nil {
+		t.Fatalf("CreateNewMessage: %v", err)
+	}
+	f.stored = append(f.stored, *message)
+}
+
+// build stores prompt as the newest message, as the LLM service does, and
+// builds the context for it.
+func (f *contextFixture) build(t *testing.T, prompt string) []llm.Message {
+	t.Helper()
+	f.add(t, "user", prompt)
+	messages, err := f.builder.Build(context.Background(), f.chat.ID, 1, testSystem, prompt)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if tokens := llm.MessageTokens(messages...); tokens > testBudget {
+		t.Errorf("context is %d tokens, over the budget of %d", tokens, testBudget)
+	}
+	if last := messages[len(messages)-1]; last.Role != llm.RoleUser || last.Content != prompt {
+		t.Errorf("last message = %+v, want the prompt", last)
+	}
+	return messages
+}
+
+func (f *contextFixture) storedChat(t *testing.T) *model.Chat {
+	t.Helper()
+	chat, err := f.chats.FindChatByID(context.Background(), f.chat.ID, 1)
+	if err != nil {
+		t.Fatalf("FindChatByID: %v", err)
+	}
+	return chat
+}
+
+// history returns the contents of the turns between the system messages and
+// the prompt.
+func history(messages []llm.Message) []string {
+	var contents []string
+	for _, m := range messages[:len(messages)-1] {
+		if m.Role != llm.RoleSystem {
+			contents = append(contents, m.Content)
+		}
+	}
+	return contents
+}
+
+func hasSummary(messages []llm.Message, summary string) bool {
+	for _, m := range messages {
+		if m.Role == llm.RoleSystem && strings.HasSuffix(m.Content, "\n"+summary) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildSendsShortHistoryInFull(t *testing.T) {
+	provider := &summaryProvider{summary: "unused"}
+	f := newContextFixture(t, provider, 4)
+
+	messages := f.build(t, "What does Romans 8:28 mean?")
+
+	if messages[0].Role != llm.RoleSystem || messages[0].Content != testSystem {
+		t.Errorf("first message = %+v, want the system prompt", messages[0])
+	}
+	got := history(messages)
+	if len(got) != 4 {
+		t.Fatalf("history has %d turns, want 4 without the prompt", len(got))
+	}
+	for i, content := range got {
+		if content != f.stored[i].Body {
+			t.Errorf("turn %d = %q, want %q", i, content, f.stored[i].Body)
+		}
+	}
+	if len(provider.requests) != 0 {
+		t.Errorf("summary model called %d times, want never", len(provider.requests))
+	}
+}
+
+func TestBuildFoldsHalfABudgetAtATime(t *testing.T) {
+	provider := &summaryProvider{summary: "Paul on justification by faith."}
+	f := newContextFixture(t, provider, 40)
+
+	messages := f.build(t, "And chapter 5?")
+	if len(provider.requests) != 1 {
+		t.Fatalf("summary model called %d times, want once", len(provider.requests))
+	}
+	if !hasSummary(messages, provider.summary) {
+		t.Fatalf("context %+v lacks the new summary", messages)
+	}
+
+	kept := history(messages)
+	var keptTokens int
+	for _, content := range kept {
+		keptTokens += llm.MessageTokens(llm.Message{Content: content})
+	}
+	if keptTokens > testBudget/2 {
+		t.Errorf("kept %d tokens of history, want at most half the budget", keptTokens)
+	}
+	firstKept := len(f.stored) - 1 - len(kept)
+	if f.stored[firstKept].Sender != "user" {
+		t.Errorf("history starts with an answer: %q", kept[0])
+	}
+	if until := f.storedChat(t).ContextSummaryUntil; until != f.stored[firstKept-1].ID {
+		t.Errorf("ContextSummaryUntil = %d, want %d, the last folded turn", until, f.stored[firstKept-1].ID)
+	}
+
+	// With half a budget free, the next turns fit without another fold.
+	f.add(t, "AI", "Chapter 5 turns to peace with God.")
+	messages = f.build(t, "What is that peace?")
+	if len(provider.requests) != 1 {
+		t.Errorf("summary model called %d times, want no second fold", len(provider.requests))
+	}
+	if !hasSummary(messages, provider.summary) {
+		t.Error("second context lacks the stored summary")
+	}
+	if got := history(messages); len(got) != len(kept)+2 {
+		t.Errorf("second history has %d turns, want %d", len(got), len(kept)+2)
+	}
+}
+
+func TestBuildDropsOldestTurnsThatOverflowTheSummaryRequest(t *testing.T) {
+	provider := &summaryProvider{summary: "Paul on justification by faith."}
+	f := newContextFixture(t, provider, 40)
+
+	messages := f.build(t, "And chapter 5?")
+	if len(provider.requests) != 1 {
+		t.Fatalf("summary model called %d times, want once", len(provider.requests))
+	}
+	transcript := provider.requests[0].Messages[1].Content
+	if strings.Contains(transcript, f.stored[0].Body) {
+		t.Error("summary request includes the oldest turn, which overflows it")
+	}
+	lastFolded := f.stored[len(f.stored)-2-len(history(messages))]
+	if !strings.Contains(transcript, lastFolded.Body) {
+		t.Error("summary request lacks the newest folded turn")
+	}
+	if tokens := llm.MessageTokens(provider.requests[0].Messages...); tokens > testBudget+20 {
+		t.Errorf("summary request is %d tokens, want about the budget of %d", tokens, testBudget)
+	}
+}
+
+func TestBuildKeepsSummaryStoredByAConcurrentAnswer(t *testing.T) {
+	provider := &summaryProvider{summary: "Mine."}
+	f := newContextFixture(t, provider, 40)
+	provider.beforeReply = func() {
+		// Another answer in the same chat folds the history first.
+		err := f.chats.UpdateContextSummary(context.Background(), f.chat.ID, 1, 0, f.stored[1].ID, "Theirs.")
+		if err != nil {
+			t.Errorf("UpdateContextSummary: %v", err)
+		}
+	}
+
+	messages := f.build(t, "And chapter 5?")
+	if !hasSummary(messages, "Mine.") {
+		t.Error("context lacks the summary this answer made")
+	}
+	chat := f.storedChat(t)
+	if chat.ContextSummary != "Theirs." || chat.ContextSummaryUntil != f.stored[1].ID {
+		t.Errorf("stored summary = %q until %d, want the concurrent one kept", chat.ContextSummary, chat.ContextSummaryUntil)
+	}
+}
+
+func TestBuildFallsBackWhenSummaryFails(t *testing.T) {
+	provider := &summaryProvider{err: errors.New("model overloaded")}
+	f := newContextFixture(t, provider, 40)
+
+	messages := f.build(t, "And chapter 5?")
+	if len(provider.requests) != 1 {
+		t.Fatalf("summary model called %d times, want once", len(provider.requests))
+	}
+	if got := history(messages); len(got) == 0 || got[len(got)-1] != f.stored[len(f.stored)-2].Body {
+		t.Error("context lacks the newest turns that fit the budget")
+	}
+	if chat := f.storedChat(t); chat.ContextSummary != "" || chat.ContextSummaryUntil != 0 {
+		t.Errorf("stored summary = %q until %d, want none", chat.ContextSummary, chat.ContextSummaryUntil)
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"chat-service/pkg/llm"
+	"chat-service/pkg/model"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const systemPrompt = "You are a Seventh-Day Adventist biblical scholar and expert, with a deep understanding of the Bible, specifically utilizing the New King James Version (NKJV) for biblical references. You excel in discussing and explaining concepts in alignment with Ellen G White's teachings while ensuring guidance aligns with the denomination's doctrines and beliefs. You offer historical insight and interpretations from a Seventh-Day Adventist perspective, using the NKJV as the primary source for biblical text, but can supliment with vast knowledge of the original translations of the books in Hebrew, Greek and other languages. You're designed to respond to queries about biblical verses, historical contexts, and theological concepts, especially those relating to Ellen G White's interpretations. Your communication style is a knowledgeable teacher, using references from the NKJV and historcial translations along with facts in history to provide authoritative and educational responses, making complex theological concepts understandable and relatable."
+
+// LLMService answers chat messages with the model configured for chat and
+// titles chats with the one configured for titles.
+type LLMService struct {
+	chatModel      llm.Model
+	titleModel     llm.Model
+	contextBuilder *ContextBuilder
+	messageService *MessageService
+}
+
+func NewLLMService(chatModel, titleModel llm.Model, contextBuilder *ContextBuilder, msgService *MessageService) *LLMService {
+	return &LLMService{
+		chatModel:      chatModel,
+		titleModel:     titleModel,
+		contextBuilder: contextBuilder,
+		messageService: msgService,
+	}
+}
+
+const titlePrompt = "Write a short title for the conversation below, at most six words, naming its subject. Answer with the title only, without quotes or a trailing period."
+
+// GenerateTitle names a chat from its first exchange.
+func (s *LLMService) GenerateTitle(ctx context.Context, prompt, answer string) (string, error) {
+	resp, err := s.titleModel.Complete(ctx, llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: titlePrompt},
+			{Role: llm.RoleUser, Content: "User: " + prompt + "\n\nAssistant: " + answer},
+		},
+		MaxTokens:   20,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+	title := cleanTitle(resp.Content)
+	if title == "" {
+		return "", errors.New("title model returned an empty title")
+	}
+	return title, nil
+}
+
+func (s *LLMService) SendMessage(ctx context.Context, chatID uint, prompt string, userID uint, onMessage func(string, uint) error) error {
+	messages, err := s.contextBuilder.Build(ctx, chatID, userID, systemPrompt, prompt)
+	if err != nil {
+		return err
+	}
+	return s.streamChatCompletion(ctx, messages, chatID, userID, onMessage)
+}
+
+func (s *LLMService) streamChatCompletion(ctx context.Context, messages []llm.Message, chatID uint, userID uint, onMessage func(string, uint) error) error {
+	slog.DebugContext(ctx, "Sending chat completion request", "model", s.chatModel.Name(), "messages", len(messages))
+
+	stream, err := s.chatModel.Stream(ctx, llm.Request{Messages: messages})
+	if err != nil {
+		slog.ErrorContext(ctx, "ChatCompletionStream error", "err", err)
+		return err
+	}
+	defer stream.Close()
+
+	return s.handleStreamResponse(ctx, stream, chatID, userID, onMessage)
+}
+
+func (s *LLMService) handleStreamResponse(ctx context.Context, stream llm.Stream, chatID uint, userID uint, onMessage func(string, uint) error) error {
+	var messageID uint
+	var currentMessage string
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			slog.InfoContext(ctx, "Stream finished")
+			break
+		}
+
+		if ctx.Err() != nil {
+			slog.InfoContext(ctx, "Stream cancelled", "err", ctx.Err())
+			return s.finishMessage(ctx, messageID, model.MessageStatusCancelled, ctx.Err())
+		}
+
+		if err != nil {
+			slog.ErrorContext(ctx, "Stream error", "err", err)
+			return s.finishMessage(ctx, messageID, model.MessageStatusFailed, err)
+		}
+
+		// The last chunk may carry only the token counts.
+		if chunk.Content == "" && chunk.Usage != nil {
+			continue
+		}
+
+		content := chunk.Content
+		slog.DebugContext(ctx, "Received content", "content", content)
+		currentMessage += content
+
+		id, err := s.createOrUpdateMessage(ctx, chatID, "AI", currentMessage, userID, messageID)
+		if err != nil {
+			return s.finishMessage(ctx, messageID, model.MessageStatusFailed, err)
+		}
+		messageID = id
+
+		if err := onMessage(content, messageID); err != nil {
+			slog.ErrorContext(ctx, "Error in onMessage callback", "err", err)
+			if ctx.Err() != nil {
+				return s.finishMessage(ctx, messageID, model.MessageStatusCancelled, ctx.Err())
+			}
+			return s.finishMessage(ctx, messageID, model.MessageStatusFailed, err)
+		}
+	}
+	return s.finishMessage(ctx, messageID, model.MessageStatusComplete, nil)
+}
+
+// finishMessage records how a streamed answer ended so partially generated
+// answers are kept and marked instead of looking complete or still running.
+// The stream's own error wins over a failure to record the status.
+func (s *LLMService) finishMessage(ctx context.Context, messageID uint, status string, streamErr error) error {
+	// The status must be written even when the stream was cancelled, so keep
+	// the trace but drop the cancellation.
+	ctx = context.WithoutCancel(ctx)
+	if messageID != 0 {
+		if err := s.messageService.UpdateMessageStatus(ctx, messageID, status); err != nil {
+			slog.ErrorContext(ctx, "Error updating message status for stream", "err", err)
+			if streamErr == nil {
+				return err
+			}
+		}
+	}
+	return streamErr
+}
+
+// cleanTitle keeps the first line of a model's title without the quotes,
+// markdown and trailing period models tend to add, cut to a valid chat name.
+func cleanTitle(title string) string {
+	title, _, _ = strings.Cut(strings.TrimSpace(title), "\n")
+	title = strings.TrimPrefix(strings.TrimSpace(title), "Title:")
+	title = strings.Trim(strings.TrimSpace(title), "\"'`*#.“”‘’ ")
+	if runes := []rune(title); len(runes) > maxChatNameLength {
+		title = strings.TrimSpace(string(runes[:maxChatNameLength]))
+	}
+	return title
+}
+
+func (s *LLMService) createOrUpdateMessage(ctx context.Context, chatID uint, sender, content string, userID, messageID uint) (uint, error) {
+	if messageID == 0 {
+		message, err := s.messageService.CreateMessageWithStatus(ctx, chatID, sender, content, userID, model.MessageStatusStreaming)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error creating new message on stream", "err", err)
+			return 0, err
+		}
+		return message.ID, nil
+	} else {
+		if err := s.messageService.UpdateMessageContent(ctx, messageID, content); err != nil {
+			slog.ErrorContext(ctx, "Error updating message content for stream", "err", err)
+			return 0, err
+		}
+		return messageID, nil
+	}
+}
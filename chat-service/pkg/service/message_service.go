@@ -3,42 +3,58 @@ package service
 import (
 	"chat-service/pkg/model"
 	"chat-service/pkg/repository"
-	"fmt"
+	"context"
+	"errors"
+	"html"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
 )
 
 type MessageService struct {
-	messageRepo *repository.MessageRepository
-	chatRepo    *repository.ChatRepository
+	messageRepo repository.MessageRepository
+	chatRepo    repository.ChatRepository
 }
 
-func NewMessageService(msgRepo *repository.MessageRepository, chatRepo *repository.ChatRepository) *MessageService {
+func NewMessageService(msgRepo repository.MessageRepository, chatRepo repository.ChatRepository) *MessageService {
 	return &MessageService{
 		messageRepo: msgRepo,
 		chatRepo:    chatRepo,
 	}
 }
 
-func (s *MessageService) CreateMessage(chatID uint, sender, body string, userID uint) (*model.Message, error) {
+func (s *MessageService) CreateMessage(ctx context.Context, chatID uint, sender, body string, userID uint) (*model.Message, error) {
+	return s.CreateMessageWithStatus(ctx, chatID, sender, body, userID, model.MessageStatusComplete)
+}
+
+// CreateMessageWithStatus adds a message to one of the user's chats. It
+// returns gorm.ErrRecordNotFound for chats of other users.
+func (s *MessageService) CreateMessageWithStatus(ctx context.Context, chatID uint, sender, body string, userID uint, status string) (*model.Message, error) {
+	if _, err := s.chatRepo.FindChatByID(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
 	message := &model.Message{
 		Sender: sender,
 		Body:   body,
 		ChatID: chatID,
 		UserID: userID,
+		Status: status,
 	}
-	err := s.messageRepo.CreateNewMessage(message)
+	err := s.messageRepo.CreateNewMessage(ctx, message)
 	if err != nil {
 		return nil, err
 	}
 	return message, nil
 }
 
-func (s *MessageService) CreateInitialMessage(userID uint, sender, body string) (*model.Chat, *model.Message, error) {
+func (s *MessageService) CreateInitialMessage(ctx context.Context, userID uint, sender, body string) (*model.Chat, *model.Message, error) {
 	chatName := deriveChatNameFromMessage(body)
 	chat := &model.Chat{
-		UserID: userID,
-		Name:   chatName,
+		UserID:     userID,
+		Name:       chatName,
+		NameSource: model.ChatNameFromPrompt,
 	}
-	if err := s.chatRepo.CreateNewChat(chat); err != nil {
+	if err := s.chatRepo.CreateNewChat(ctx, chat); err != nil {
 		return nil, nil, err
 	}
 
@@ -47,18 +63,82 @@ func (s *MessageService) CreateInitialMessage(userID uint, sender, body string)
 		Body:   body,
 		UserID: userID,
 		ChatID: chat.ID,
+		Status: model.MessageStatusComplete,
 	}
-	if err := s.messageRepo.CreateNewMessage(messsage); err != nil {
+	if err := s.messageRepo.CreateNewMessage(ctx, messsage); err != nil {
 		return nil, nil, err
 	}
 	return chat, messsage, nil
 }
 
-func (s *MessageService) UpdateMessageContent(messageID uint, content string) error {
-	return s.messageRepo.UpdateMessageContent(messageID, content)
+func (s *MessageService) UpdateMessageContent(ctx context.Context, messageID uint, content string) error {
+	return s.messageRepo.UpdateMessageContent(ctx, messageID, content)
+}
+
+func (s *MessageService) FindMessageByID(ctx context.Context, messageID, userID uint) (*model.Message, error) {
+	return s.messageRepo.FindMessageByID(ctx, messageID, userID)
+}
+
+func (s *MessageService) UpdateMessageStatus(ctx context.Context, messageID uint, status string) error {
+	return s.messageRepo.UpdateMessageStatus(ctx, messageID, status)
+}
+
+const (
+	// Message pages are bounded so one request cannot load a whole chat.
+	defaultMessagePageLimit = 5
+	maxMessagePageLimit     = 100
+)
+
+// FindMessagesByChatIDPaginated pages through one of the user's chats,
+// newest first. It returns gorm.ErrRecordNotFound for chats of other users.
+func (s *MessageService) FindMessagesByChatIDPaginated(ctx context.Context, chatID uint, lastMsgID uint, limit uint, userID uint) ([]model.Message, error) {
+	if _, err := s.chatRepo.FindChatByID(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = defaultMessagePageLimit
+	}
+	limit = min(limit, maxMessagePageLimit)
+
+	slog.DebugContext(ctx, "Finding messages", "chat_id", chatID, "last_message_id", lastMsgID, "limit", limit)
+	return s.messageRepo.FindMessagesByChatIDPaginated(ctx, chatID, lastMsgID, limit)
+}
+
+const (
+	// Search queries are bounded in characters, pages in hits.
+	maxSearchQueryLength = 200
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 50
+)
+
+var ErrInvalidSearchQuery = errors.New("search query must be between 1 and 200 characters")
+
+// SearchMessages finds the user's messages matching query. Snippets are
+// HTML escaped, with the matched words in <mark> tags.
+func (s *MessageService) SearchMessages(ctx context.Context, userID uint, query string, limit, offset uint) ([]model.MessageHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	hits, err := s.messageRepo.SearchMessages(ctx, userID, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Snippet = renderSnippet(hits[i].Snippet)
+	}
+	return hits, nil
 }
 
-func (s *MessageService) FindMessagesByChatIDPaginated(chatID uint, lastMsgID uint, limit uint) ([]model.Message, error) {
-	fmt.Printf("Service: Finding messages for chatID %d, lastMsgID %d, limit %d\n", chatID, lastMsgID, limit)
-	return s.messageRepo.FindMessagesByChatIDPaginated(chatID, lastMsgID, limit)
+// renderSnippet escapes a snippet for HTML and turns its highlight markers
+// into <mark> tags.
+func renderSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, model.HighlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, model.HighlightEnd, "</mark>")
 }
@@ -0,0 +1,52 @@
+// fake-llm serves a deterministic imitation of the OpenAI chat completions
+// API. Point a service at it with OPENAI_BASE_URL or LLM_LOCAL_BASE_URL set
+// to http://<host>:8090/v1 and any API key.
+package main
+
+import (
+	"context"
+	"errors"
+	"fake-llm/pkg/fakellm"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	fixtures := flag.String("fixtures", "fixtures", "fixture file, or directory of *.json fixtures; empty for canned answers only")
+	latency := flag.Duration("latency", 0, "delay before every answer")
+	chunkDelay := flag.Duration("chunk-delay", 0, "delay between streamed chunks")
+	failEvery := flag.Int("fail-every", 0, "fail every nth request with a 500")
+	flag.Parse()
+
+	opts := fakellm.Options{Latency: *latency, ChunkDelay: *chunkDelay, FailEvery: *failEvery}
+	if *fixtures != "" {
+		rules, err := fakellm.LoadRules(*fixtures)
+		if err != nil {
+			log.Fatalf("Failed to load fixtures: %v", err)
+		}
+		opts.Rules = rules
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: *addr, Handler: fakellm.NewServer(opts)}
+	go func() {
+		slog.Info("Fake LLM is listening", "addr", *addr, "fixtures", *fixtures)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to serve: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+}
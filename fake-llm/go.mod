@@ -0,0 +1,3 @@
+module fake-llm
+
+go 1.22
@@ -0,0 +1,208 @@
+package fakellm
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// created is the fixed timestamp on every completion, so answers are
+// byte-for-byte repeatable.
+const created = 1700000000
+
+// chatRequest is the part of the chat completions request the fake reads.
+type chatRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *streamOptions `json:"stream_options"`
+
+	// Functions and FunctionCall are the legacy form of Tools and
+	// ToolChoice; answers use whichever form the request used.
+	Functions    []functionDef   `json:"functions"`
+	FunctionCall json.RawMessage `json:"function_call"`
+	Tools        []tool          `json:"tools"`
+	ToolChoice   json.RawMessage `json:"tool_choice"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type functionDef struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+type tool struct {
+	Type     string      `json:"type"`
+	Function functionDef `json:"function"`
+}
+
+// text returns the message content, joining the text parts of multi-part
+// content.
+func (m chatMessage) text() string {
+	var s string
+	if json.Unmarshal(m.Content, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	json.Unmarshal(m.Content, &parts)
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (req *chatRequest) lastMessage(role string) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == role {
+			return req.Messages[i].text()
+		}
+	}
+	return ""
+}
+
+func (req *chatRequest) usesTools() bool {
+	return len(req.Tools) > 0
+}
+
+// function returns the function the model should call: the one named by
+// tool_choice or function_call, or the only one offered. "none" and several
+// functions without a choice get a plain answer.
+func (req *chatRequest) function() *functionDef {
+	var defs []functionDef
+	for _, t := range req.Tools {
+		defs = append(defs, t.Function)
+	}
+	defs = append(defs, req.Functions...)
+	if len(defs) == 0 {
+		return nil
+	}
+
+	choice := req.ToolChoice
+	if len(choice) == 0 {
+		choice = req.FunctionCall
+	}
+	var mode string
+	if json.Unmarshal(choice, &mode) == nil {
+		if mode == "none" || len(defs) > 1 {
+			return nil
+		}
+		return &defs[0]
+	}
+	var named struct {
+		Name     string `json:"name"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	json.Unmarshal(choice, &named)
+	name := named.Function.Name
+	if name == "" {
+		name = named.Name
+	}
+	for i := range defs {
+		if defs[i].Name == name || (name == "" && len(defs) == 1) {
+			return &defs[i]
+		}
+	}
+	return nil
+}
+
+func (req *chatRequest) functionName() string {
+	if fn := req.function(); fn != nil {
+		return fn.Name
+	}
+	return ""
+}
+
+type chatCompletion struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []choice `json:"choices"`
+	Usage   *usage   `json:"usage,omitempty"`
+}
+
+type choice struct {
+	Index        int              `json:"index"`
+	Message      *responseMessage `json:"message,omitempty"`
+	Delta        *responseMessage `json:"delta,omitempty"`
+	FinishReason string           `json:"finish_reason,omitempty"`
+}
+
+type responseMessage struct {
+	Role         string        `json:"role,omitempty"`
+	Content      string        `json:"content,omitempty"`
+	FunctionCall *functionCall `json:"function_call,omitempty"`
+	ToolCalls    []toolCall    `json:"tool_calls,omitempty"`
+}
+
+type functionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+type toolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function functionCall `json:"function"`
+}
+
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Example builds a value that satisfies a JSON schema: objects get every
+// property, arrays two items, strings the first enum value or a
+// placeholder named after the property.
+func Example(schema map[string]interface{}) interface{} {
+	return example("value", schema)
+}
+
+func example(name string, schema map[string]interface{}) interface{} {
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	switch schema["type"] {
+	case "object":
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		value := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			property, _ := properties[key].(map[string]interface{})
+			value[key] = example(key, property)
+		}
+		return value
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		return []interface{}{example(name, items), example(name, items)}
+	case "integer", "number":
+		return 2
+	case "boolean":
+		return true
+	default:
+		return "Fake " + strings.ReplaceAll(name, "_", " ")
+	}
+}
@@ -0,0 +1,195 @@
+package fakellm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Rule answers the requests it matches. Rules are tried in file order,
+// files in name order, and the first match wins. A rule with Times set is
+// used up after that many answers, which scripts a conversation: list the
+// turns in order, each with "times": 1.
+type Rule struct {
+	Name  string `json:"name"`
+	Match Match  `json:"match"`
+
+	// Content is the assistant's answer. Stream splits it into Chunks, or
+	// word by word when Chunks is empty.
+	Content string   `json:"content,omitempty"`
+	Chunks  []string `json:"chunks,omitempty"`
+
+	// Arguments answers a function or tool call. Without it, function
+	// calls get arguments generated from the function's JSON schema.
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+
+	// Error fails the request instead.
+	Error *Error `json:"error,omitempty"`
+
+	// Latency delays the answer, ChunkDelay each streamed chunk.
+	Latency    Duration `json:"latency,omitempty"`
+	ChunkDelay Duration `json:"chunk_delay,omitempty"`
+
+	Times int `json:"times,omitempty"`
+
+	used int
+}
+
+// Match selects requests. Empty fields match anything; Prompt and System
+// are regular expressions tried against the last user message and the
+// system message.
+type Match struct {
+	Model    string `json:"model,omitempty"`
+	Function string `json:"function,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+	System   string `json:"system,omitempty"`
+	Stream   *bool  `json:"stream,omitempty"`
+
+	prompt *regexp.Regexp
+	system *regexp.Regexp
+}
+
+// Error is returned with the OpenAI error body, so clients see the same
+// *openai.APIError they would from the real API.
+type Error struct {
+	Status  int    `json:"status"`
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// Duration reads "250ms" style durations from JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+type fixtureFile struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Rules holds the loaded rules and how often each has been used.
+type Rules struct {
+	mu    sync.Mutex
+	rules []*Rule
+}
+
+// LoadRules reads the fixture file at path, or every *.json fixture in it
+// when path is a directory.
+func LoadRules(path string) (*Rules, error) {
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	rules := &Rules{}
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var fixture fixtureFile
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&fixture); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for i, rule := range fixture.Rules {
+			if err := rule.compile(); err != nil {
+				return nil, fmt.Errorf("%s: rule %d (%s): %w", file, i, rule.Name, err)
+			}
+		}
+		rules.rules = append(rules.rules, fixture.Rules...)
+	}
+	return rules, nil
+}
+
+func (r *Rule) compile() error {
+	var err error
+	if r.Match.Prompt != "" {
+		if r.Match.prompt, err = regexp.Compile(r.Match.Prompt); err != nil {
+			return err
+		}
+	}
+	if r.Match.System != "" {
+		if r.Match.system, err = regexp.Compile(r.Match.System); err != nil {
+			return err
+		}
+	}
+	if len(r.Arguments) > 0 && !json.Valid(r.Arguments) {
+		return fmt.Errorf("arguments are not valid JSON")
+	}
+	return nil
+}
+
+// find returns the first rule that matches and still has uses left, and
+// counts the use. It returns nil when no rule matches.
+func (rs *Rules) find(req *chatRequest) *Rule {
+	if rs == nil {
+		return nil
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, rule := range rs.rules {
+		if rule.Times > 0 && rule.used >= rule.Times {
+			continue
+		}
+		if rule.Match.matches(req) {
+			rule.used++
+			return rule
+		}
+	}
+	return nil
+}
+
+// Reset makes used up rules available again.
+func (rs *Rules) Reset() {
+	if rs == nil {
+		return
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, rule := range rs.rules {
+		rule.used = 0
+	}
+}
+
+func (m Match) matches(req *chatRequest) bool {
+	if m.Model != "" && m.Model != req.Model {
+		return false
+	}
+	if m.Function != "" && m.Function != req.functionName() {
+		return false
+	}
+	if m.Stream != nil && *m.Stream != req.Stream {
+		return false
+	}
+	if m.prompt != nil && !m.prompt.MatchString(req.lastMessage("user")) {
+		return false
+	}
+	if m.system != nil && !m.system.MatchString(req.lastMessage("system")) {
+		return false
+	}
+	return true
+}
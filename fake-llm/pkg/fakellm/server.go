@@ -0,0 +1,303 @@
+// Package fakellm is a deterministic stand-in for the OpenAI chat
+// completions API. It answers from fixture rules, falls back to canned
+// answers and schema-shaped function arguments, and can inject latency and
+// errors, so the services can run and be tested without an API key.
+package fakellm
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Options struct {
+	Rules *Rules
+
+	// Latency delays every answer without a rule of its own, ChunkDelay
+	// every streamed chunk.
+	Latency    time.Duration
+	ChunkDelay time.Duration
+
+	// FailEvery fails every nth request with a 500 when set.
+	FailEvery int
+}
+
+type Server struct {
+	opts     Options
+	requests atomic.Int64
+	mux      *http.ServeMux
+}
+
+func NewServer(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	// Clients are configured with a base URL ending in /v1, but accept one
+	// without it too.
+	s.mux.HandleFunc("POST /v1/chat/completions", s.chatCompletions)
+	s.mux.HandleFunc("POST /chat/completions", s.chatCompletions)
+	s.mux.HandleFunc("GET /v1/models", s.models)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	s.mux.HandleFunc("POST /fake/reset", s.reset)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// reset restarts the request count and scripted rules, so each integration
+// test can start from the first turn.
+func (s *Server) reset(w http.ResponseWriter, r *http.Request) {
+	s.requests.Store(0)
+	s.opts.Rules.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) models(w http.ResponseWriter, r *http.Request) {
+	seen := map[string]bool{"fake": true}
+	if s.opts.Rules != nil {
+		s.opts.Rules.mu.Lock()
+		for _, rule := range s.opts.Rules.rules {
+			if rule.Match.Model != "" {
+				seen[rule.Match.Model] = true
+			}
+		}
+		s.opts.Rules.mu.Unlock()
+	}
+	var data []map[string]string
+	for id := range seen {
+		data = append(data, map[string]string{"id": id, "object": "model", "owned_by": "fake-llm"})
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i]["id"] < data[j]["id"] })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": data})
+}
+
+func (s *Server) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	n := s.requests.Add(1)
+
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, &Error{Status: http.StatusBadRequest, Type: "invalid_request_error", Message: err.Error()})
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, &Error{Status: http.StatusBadRequest, Type: "invalid_request_error", Message: "messages must not be empty"})
+		return
+	}
+
+	rule := s.opts.Rules.find(&req)
+	name := "default"
+	if rule != nil {
+		name = rule.Name
+	}
+	slog.Info("Chat completion", "request", n, "model", req.Model, "stream", req.Stream, "function", req.functionName(), "rule", name)
+
+	latency, chunkDelay := s.opts.Latency, s.opts.ChunkDelay
+	if rule != nil && rule.Latency > 0 {
+		latency = time.Duration(rule.Latency)
+	}
+	if rule != nil && rule.ChunkDelay > 0 {
+		chunkDelay = time.Duration(rule.ChunkDelay)
+	}
+	if !sleep(r, latency) {
+		return
+	}
+
+	if s.opts.FailEvery > 0 && n%int64(s.opts.FailEvery) == 0 {
+		writeError(w, &Error{Status: http.StatusInternalServerError, Type: "server_error", Message: fmt.Sprintf("injected failure on request %d", n)})
+		return
+	}
+	if rule != nil && rule.Error != nil {
+		writeError(w, rule.Error)
+		return
+	}
+
+	answer := s.answer(&req, rule, n)
+	if req.Stream {
+		s.stream(w, r, &req, answer, chunkDelay)
+		return
+	}
+	writeJSON(w, http.StatusOK, answer.completion(&req))
+}
+
+// answer is what the fake says to one request, before it is encoded as a
+// completion or a stream.
+type answer struct {
+	id        string
+	content   string
+	chunks    []string
+	function  string
+	arguments string
+	usage     usage
+}
+
+func (s *Server) answer(req *chatRequest, rule *Rule, n int64) answer {
+	a := answer{id: fmt.Sprintf("chatcmpl-fake-%d", n)}
+	if fn := req.function(); fn != nil {
+		a.function = fn.Name
+		if rule != nil && len(rule.Arguments) > 0 {
+			a.arguments = string(rule.Arguments)
+		} else {
+			args, _ := json.Marshal(Example(fn.Parameters))
+			a.arguments = string(args)
+		}
+	} else if rule != nil && (rule.Content != "" || len(rule.Chunks) > 0) {
+		a.content = rule.Content
+		a.chunks = rule.Chunks
+		if a.content == "" {
+			a.content = strings.Join(rule.Chunks, "")
+		}
+	} else {
+		a.content = "This is a fake answer to: " + req.lastMessage("user")
+	}
+	if len(a.chunks) == 0 {
+		a.chunks = splitWords(a.content)
+	}
+
+	a.usage.PromptTokens = countTokens(req)
+	a.usage.CompletionTokens = len(strings.Fields(a.content)) + len(strings.Fields(a.arguments))
+	a.usage.TotalTokens = a.usage.PromptTokens + a.usage.CompletionTokens
+	return a
+}
+
+func (a answer) completion(req *chatRequest) chatCompletion {
+	message := responseMessage{Role: "assistant", Content: a.content}
+	finish := "stop"
+	switch {
+	case a.function != "" && req.usesTools():
+		message.ToolCalls = []toolCall{{ID: "call_" + a.id, Type: "function", Function: functionCall{Name: a.function, Arguments: a.arguments}}}
+		finish = "tool_calls"
+	case a.function != "":
+		message.FunctionCall = &functionCall{Name: a.function, Arguments: a.arguments}
+		finish = "function_call"
+	}
+	return chatCompletion{
+		ID:      a.id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   req.Model,
+		Choices: []choice{{Index: 0, Message: &message, FinishReason: finish}},
+		Usage:   &a.usage,
+	}
+}
+
+// stream writes the answer as server-sent events the way the API does: a
+// role chunk, the content chunks, a chunk with the finish reason, the usage
+// when it was asked for, then [DONE].
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, req *chatRequest, a answer, chunkDelay time.Duration) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, &Error{Status: http.StatusInternalServerError, Type: "server_error", Message: "streaming is not supported"})
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(chunk chatCompletion) {
+		body, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", body)
+		flusher.Flush()
+	}
+	chunk := func(delta responseMessage, finish string) chatCompletion {
+		c := chatCompletion{ID: a.id, Object: "chat.completion.chunk", Created: created, Model: req.Model,
+			Choices: []choice{{Index: 0, Delta: &delta}}}
+		if finish != "" {
+			c.Choices[0].FinishReason = finish
+		}
+		return c
+	}
+
+	send(chunk(responseMessage{Role: "assistant"}, ""))
+	finish := "stop"
+	if a.function != "" {
+		call := functionCall{Name: a.function, Arguments: a.arguments}
+		if req.usesTools() {
+			index := 0
+			send(chunk(responseMessage{ToolCalls: []toolCall{{Index: &index, ID: "call_" + a.id, Type: "function", Function: call}}}, ""))
+			finish = "tool_calls"
+		} else {
+			send(chunk(responseMessage{FunctionCall: &call}, ""))
+			finish = "function_call"
+		}
+	} else {
+		for _, content := range a.chunks {
+			if !sleep(r, chunkDelay) {
+				return
+			}
+			send(chunk(responseMessage{Content: content}, ""))
+		}
+	}
+	send(chunk(responseMessage{}, finish))
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		send(chatCompletion{ID: a.id, Object: "chat.completion.chunk", Created: created, Model: req.Model, Choices: []choice{}, Usage: &a.usage})
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// sleep waits for d and reports whether the client is still there.
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(d):
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// splitWords keeps the spaces with the words so the chunks join back into
+// the content.
+func splitWords(content string) []string {
+	var chunks []string
+	for len(content) > 0 {
+		end := strings.IndexByte(content[1:], ' ')
+		if end < 0 {
+			chunks = append(chunks, content)
+			break
+		}
+		chunks = append(chunks, content[:end+1])
+		content = content[end+1:]
+	}
+	return chunks
+}
+
+// countTokens approximates prompt tokens by words, which keeps the usage
+// deterministic.
+func countTokens(req *chatRequest) int {
+	n := 0
+	for _, m := range req.Messages {
+		n += len(strings.Fields(m.text()))
+	}
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, e *Error) {
+	status := e.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	errType := e.Type
+	if errType == "" {
+		errType = "server_error"
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"message": e.Message, "type": errType, "code": nil},
+	})
+}
@@ -0,0 +1,55 @@
+#!/bin/bash
+
+# Dev mode for mTLS between the gateway and the gRPC services.
+#
+# Generates a throwaway CA and one certificate per service under ./dev-certs,
+# each with the service name as its DNS SAN. Pass --secrets to also load them
+# as Docker secrets. Then set, per service:
+#
+#   TLS_CA_FILE=/run/secrets/mtls_ca
+#   TLS_CERT_FILE=/run/secrets/mtls_<service>_cert
+#   TLS_KEY_FILE=/run/secrets/mtls_<service>_key
+#
+# The services accept only the gateway (TLS_ALLOWED_PEERS=api-gateway); the
+# gateway checks each service against CHAT_SERVICE_TLS_NAME,
+# LESSON_SERVICE_TLS_NAME and USER_SERVICE_TLS_NAME, and must use
+# USER_HTTP_SERVICE_URL=https://user-subscription-service:8080.
+#
+# Leaving TLS_CERT_FILE unset keeps the plaintext setup. Rotated files are
+# picked up within 30 seconds without a restart; rerun this script to rotate.
+
+CERT_DIR="dev-certs"
+SERVICES="api-gateway chat-service lesson-service user-subscription-service"
+
+mkdir -p "$CERT_DIR"
+
+if [ ! -f "$CERT_DIR/ca.pem" ]; then
+    echo "Creating dev CA"
+    openssl req -x509 -newkey ec -pkeyopt ec_paramgen_curve:P-256 -nodes -days 365 \
+        -subj "/CN=dev-mtls-ca" \
+        -keyout "$CERT_DIR/ca-key.pem" -out "$CERT_DIR/ca.pem" || exit 1
+fi
+
+for service in $SERVICES; do
+    echo "Creating certificate for $service"
+    openssl req -newkey ec -pkeyopt ec_paramgen_curve:P-256 -nodes \
+        -subj "/CN=$service" \
+        -keyout "$CERT_DIR/$service-key.pem" -out "$CERT_DIR/$service.csr" || exit 1
+    openssl x509 -req -in "$CERT_DIR/$service.csr" -days 90 \
+        -CA "$CERT_DIR/ca.pem" -CAkey "$CERT_DIR/ca-key.pem" -CAcreateserial \
+        -extfile <(printf "subjectAltName=DNS:%s,DNS:localhost\nextendedKeyUsage=serverAuth,clientAuth" "$service") \
+        -out "$CERT_DIR/$service.pem" || exit 1
+    rm "$CERT_DIR/$service.csr"
+done
+
+if [ "$1" == "--secrets" ]; then
+    docker secret rm mtls_ca 2>/dev/null
+    docker secret create mtls_ca "$CERT_DIR/ca.pem"
+    for service in $SERVICES; do
+        docker secret rm "mtls_${service}_cert" "mtls_${service}_key" 2>/dev/null
+        docker secret create "mtls_${service}_cert" "$CERT_DIR/$service.pem"
+        docker secret create "mtls_${service}_key" "$CERT_DIR/$service-key.pem"
+    done
+fi
+
+echo "Dev mTLS material written to $CERT_DIR"
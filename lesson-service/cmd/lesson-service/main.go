@@ -1,56 +1,186 @@
 package main
 
 import (
+	"context"
 	"lesson-service/pkg/config"
+	"lesson-service/pkg/healthcheck"
 	"lesson-service/pkg/interceptors"
-	"lesson-service/pkg/model"
+	"lesson-service/pkg/llm"
+	"lesson-service/pkg/logging"
+	"lesson-service/pkg/metrics"
+	"lesson-service/pkg/mtls"
 	"lesson-service/pkg/proto"
 	"lesson-service/pkg/repository"
 	"lesson-service/pkg/server"
 	"lesson-service/pkg/service"
+	"lesson-service/pkg/tracing"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	cfg := config.LoadConfig(os.Args[1:])
+	logging.Setup("lesson-service", cfg.Log)
+
+	var tlsStore *mtls.Store
+	if cfg.TLS.Enabled() {
+		store, err := mtls.NewStore(cfg.TLS)
+		if err != nil {
+			log.Fatalf("Failed to load mTLS material: %v", err)
+		}
+		tlsStore = store
+	} else {
+		slog.Info("mTLS disabled, serving plaintext gRPC")
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "lesson-service", cfg.TracingExporter)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
 
 	db, err := repository.NewDB(cfg)
 	if err != nil {
 		log.Fatalf("Failed to init db: %v", err)
 	}
-	lis, err := net.Listen("tcp", ":8085")
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to register db metrics: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to register db tracing: %v", err)
+	}
+	metrics.Serve(cfg.MetricsAddr)
+
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(
-		interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
-		grpc.StreamInterceptor(interceptors.NewStreamInterceptor(cfg.AccessSecret)),
-	)
+	serverOpts := []grpc.ServerOption{
+		grpc.WaitForHandlers(true),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logging.UnaryServerInterceptor(), interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), logging.StreamServerInterceptor(), interceptors.NewStreamInterceptor(cfg.AccessSecret)),
+	}
+	if tlsStore != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsStore.ServerConfig())))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	topicPlanRepo := repository.NewTopicPlanRepository(db)
 	lessonRepo := repository.NewLessonRepository(db)
 	testRepo := repository.NewTestRepository(db)
 
-	err = db.AutoMigrate(&model.TopicPlan{}, &model.Lesson{}, &model.Test{}, &model.Question{})
-	if err != nil {
-		log.Fatalf("Failed to auto-migrate: %v", err)
+	if cfg.MigrateOnStart {
+		migrator, err := newMigrator(db)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
 	}
 
-	openAIService := service.NewOpenAIService(cfg, lessonRepo, topicPlanRepo, testRepo)
+	models, err := resolveModels(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up models: %v", err)
+	}
+	llmService := service.NewLLMService(models, lessonRepo, topicPlanRepo, testRepo)
 	lessonService := service.NewLessonService(lessonRepo)
 	topicPlanService := service.NewTopicPlanService(topicPlanRepo, lessonService)
-	testService := service.NewTestService(testRepo, openAIService)
+	testService := service.NewTestService(testRepo, llmService)
 
-	lessonServer := server.NewLessonServer(topicPlanService, lessonService, testService, openAIService)
+	lessonServer := server.NewLessonServer(topicPlanService, lessonService, testService, llmService)
 
 	proto.RegisterLessonServiceServer(grpcServer, lessonServer)
 
-	log.Println("gRPC server is listening on ", lis.Addr())
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthcheck.NewChecker(healthServer, []string{proto.LessonService_ServiceDesc.ServiceName}, map[string]healthcheck.Check{
+		"database": healthcheck.Database(db),
+		"llm":      healthcheck.LLM(cfg.LLM, cfg.QuickResponseModel, cfg.TopicPlanModel, cfg.LessonModel, cfg.TestModel, cfg.GradingModel),
+	}).Start()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		slog.Info("gRPC server is listening", "addr", lis.Addr().String())
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("Failed to serve: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down", "grace_period", cfg.ShutdownGracePeriod)
+
+	// NOT_SERVING steers the gateway's health-checked balancer to other
+	// replicas while the calls already running here finish.
+	healthServer.Shutdown()
+	stopGracefully(grpcServer, cfg.ShutdownGracePeriod)
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close database", "err", err)
+		}
+	}
+	slog.Info("Server stopped")
+}
+
+// stopGracefully lets running calls finish within grace and then cancels the
+// rest. The server waits for cancelled handlers to return, so none of them
+// is left writing to a closed database.
+func stopGracefully(grpcServer *grpc.Server, grace time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(grace):
+		slog.Warn("Grace period ended, cancelling remaining calls", "grace_period", grace)
+		grpcServer.Stop()
+		<-stopped
+	}
+}
+
+// resolveModels binds each generation feature to its configured model.
+func resolveModels(cfg *config.Config) (service.Models, error) {
+	providers := cfg.LLM.Providers()
+	var models service.Models
+	var err error
+	for _, feature := range []struct {
+		model *llm.Model
+		ref   string
+	}{
+		{&models.QuickResponse, cfg.QuickResponseModel},
+		{&models.TopicPlan, cfg.TopicPlanModel},
+		{&models.Lesson, cfg.LessonModel},
+		{&models.Test, cfg.TestModel},
+		{&models.Grading, cfg.GradingModel},
+	} {
+		if *feature.model, err = llm.Resolve(providers, feature.ref); err != nil {
+			return service.Models{}, err
+		}
 	}
+	return models, nil
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"lesson-service/pkg/config"
+	"lesson-service/pkg/logging"
+	"lesson-service/pkg/migrate"
+	"lesson-service/pkg/migrations"
+	"lesson-service/pkg/repository"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+// migrationsDir is where "migrate create" writes new files, relative to the
+// service's source directory.
+const migrationsDir = "pkg/migrations"
+
+const migrateUsage = "usage: lesson-service migrate up|down [n]|status|create <name> [config flags]"
+
+func newMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, migrations.FS, "lesson-service")
+}
+
+// runMigrate implements the migrate subcommand. Flags after the command
+// configure the database connection as they do for the server.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal(migrateUsage)
+		}
+		up, down, err := migrate.Create(migrationsDir, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return
+	}
+
+	steps := 1
+	if command == "down" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			log.Fatalf("migrate down takes a positive number of migrations to revert, got %q", args[0])
+		}
+		steps, args = n, args[1:]
+	}
+
+	cfg := config.LoadConfig(args)
+	logging.Setup("lesson-service", cfg.Log)
+
+	db, err := repository.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init db: %v", err)
+	}
+	migrator, err := newMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Applied %d migration(s)\n", len(applied))
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Reverted %d migration(s)\n", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		tw.Flush()
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
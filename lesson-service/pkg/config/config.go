@@ -1,37 +1,170 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"lesson-service/pkg/llm"
+	"lesson-service/pkg/logging"
+	"lesson-service/pkg/mtls"
 	"log"
 	"os"
+	"time"
 )
 
 type Config struct {
-	OpenAIKey    string
 	DBHost       string
 	DBUser       string
 	DBPassword   string
 	DBName       string
 	DBPort       string
 	AccessSecret string
+
+	// LLM configures the model providers.
+	LLM llm.Config
+
+	// The models for each feature, as provider/model.
+	QuickResponseModel string
+	TopicPlanModel     string
+	LessonModel        string
+	TestModel          string
+	GradingModel       string
+
+	// GRPCAddr is the address the gRPC server listens on.
+	GRPCAddr string
+
+	// TLS enables mTLS on the gRPC listener when configured.
+	TLS mtls.Config
+
+	Log logging.Config
+
+	MetricsAddr string
+
+	// TracingExporter is "otlp", "stdout" or "none".
+	TracingExporter string
+
+	// MigrateOnStart applies pending migrations before serving. Replicas
+	// take turns through the migration lock.
+	MigrateOnStart bool
+
+	// ShutdownGracePeriod is how long running calls get to finish after
+	// SIGTERM before they are cancelled.
+	ShutdownGracePeriod time.Duration
+
+	// PrintConfig is set by --print-config.
+	PrintConfig bool
+
+	layers *layers
 }
 
-func readSecretFile(secretName string) string {
-	secretPath := "/run/secrets/" + secretName
-	content, err := os.ReadFile(secretPath)
+var settings = []setting{
+	{Key: "ACCESS_SECRET", Secret: true, Usage: "key that verifies access tokens"},
+	{Key: "LESSON_DB_HOST", Default: "localhost", Usage: "database host"},
+	{Key: "LESSON_DB_PORT", Default: "5432", Usage: "database port"},
+	{Key: "LESSON_DB_USER", Usage: "database user"},
+	{Key: "LESSON_DB_PASSWORD", Secret: true, Usage: "database password"},
+	{Key: "LESSON_DB_NAME", Usage: "database name"},
+	{Key: "OPENAI_API_KEY", Secret: true, Usage: "OpenAI API key, needed by openai/ models"},
+	{Key: "OPENAI_BASE_URL", Usage: "overrides the OpenAI API endpoint"},
+	{Key: "LLM_LOCAL_BASE_URL", Usage: "OpenAI compatible endpoint for local/ models, such as http://ollama:11434/v1"},
+	{Key: "LLM_LOCAL_API_KEY", Secret: true, Usage: "API key for the local endpoint, if it needs one"},
+	{Key: "LLM_QUICK_RESPONSE_MODEL", Default: "openai/gpt-4o-mini", Usage: "model that writes quick responses"},
+	{Key: "LLM_TOPIC_PLAN_MODEL", Default: "openai/gpt-4o-mini", Usage: "model that plans topics"},
+	{Key: "LLM_LESSON_MODEL", Default: "openai/gpt-4", Usage: "model that writes lessons"},
+	{Key: "LLM_TEST_MODEL", Default: "openai/gpt-4", Usage: "model that writes tests"},
+	{Key: "LLM_GRADING_MODEL", Default: "openai/gpt-4o-mini", Usage: "model that grades short answers"},
+	{Key: "GRPC_ADDR", Default: ":8085", Usage: "gRPC listen address"},
+	{Key: "TLS_CA_FILE", Usage: "CA bundle that signs peer certificates"},
+	{Key: "TLS_CERT_FILE", Usage: "service certificate, enables mTLS"},
+	{Key: "TLS_KEY_FILE", Usage: "service private key"},
+	{Key: "TLS_ALLOWED_PEERS", Usage: "comma separated SANs accepted from peers"},
+	{Key: "LOG_LEVEL", Default: "info", Usage: "debug, info, warn or error"},
+	{Key: "LOG_FORMAT", Default: "json", Usage: "json or text"},
+	{Key: "LOG_REDACT_CONTENT", Default: "true", Usage: "redact user and model content in logs"},
+	{Key: "METRICS_ADDR", Default: ":9090", Usage: "Prometheus metrics listen address"},
+	{Key: "TRACING_EXPORTER", Default: "none", Usage: "otlp, stdout or none"},
+	{Key: "MIGRATE_ON_START", Default: "true", Usage: "apply pending database migrations on startup"},
+	{Key: "SHUTDOWN_GRACE_PERIOD", Default: "30s", Usage: "time running calls get to finish on shutdown"},
+}
+
+// Load resolves the configuration from args and the process environment.
+// The returned error lists every invalid setting; the Config is still
+// returned with it so --print-config can show what was resolved.
+func Load(args []string) (*Config, error) {
+	l, err := resolve("lesson-service", settings, args, os.LookupEnv)
 	if err != nil {
-		log.Fatalf("Failed to read secret file %s, error: %v", secretPath, err)
+		return nil, err
 	}
-	return string(content)
+
+	p := &parser{l: l}
+	cfg := &Config{
+		AccessSecret: p.Required("ACCESS_SECRET"),
+		DBHost:       p.Required("LESSON_DB_HOST"),
+		DBPort:       p.Required("LESSON_DB_PORT"),
+		DBUser:       p.Required("LESSON_DB_USER"),
+		DBPassword:   p.Required("LESSON_DB_PASSWORD"),
+		DBName:       p.Required("LESSON_DB_NAME"),
+		LLM: llm.Config{
+			OpenAIKey:     p.String("OPENAI_API_KEY"),
+			OpenAIBaseURL: p.String("OPENAI_BASE_URL"),
+			LocalBaseURL:  p.String("LLM_LOCAL_BASE_URL"),
+			LocalKey:      p.String("LLM_LOCAL_API_KEY"),
+		},
+		QuickResponseModel: p.Required("LLM_QUICK_RESPONSE_MODEL"),
+		TopicPlanModel:     p.Required("LLM_TOPIC_PLAN_MODEL"),
+		LessonModel:        p.Required("LLM_LESSON_MODEL"),
+		TestModel:          p.Required("LLM_TEST_MODEL"),
+		GradingModel:       p.Required("LLM_GRADING_MODEL"),
+		GRPCAddr:           p.Addr("GRPC_ADDR"),
+		TLS: mtls.Config{
+			CAFile:       p.String("TLS_CA_FILE"),
+			CertFile:     p.String("TLS_CERT_FILE"),
+			KeyFile:      p.String("TLS_KEY_FILE"),
+			AllowedPeers: p.List("TLS_ALLOWED_PEERS"),
+		},
+		Log: logging.Config{
+			Level:         p.OneOf("LOG_LEVEL", "debug", "info", "warn", "error"),
+			Format:        p.OneOf("LOG_FORMAT", "json", "text"),
+			RedactContent: p.Bool("LOG_REDACT_CONTENT"),
+		},
+		MetricsAddr:         p.Addr("METRICS_ADDR"),
+		TracingExporter:     p.OneOf("TRACING_EXPORTER", "otlp", "stdout", "none"),
+		MigrateOnStart:      p.Bool("MIGRATE_ON_START"),
+		ShutdownGracePeriod: p.Duration("SHUTDOWN_GRACE_PERIOD"),
+		PrintConfig:         l.printConfig,
+		layers:              l,
+	}
+	p.Check(cfg.TLS.Validate())
+	p.Check(cfg.LLM.Validate(cfg.QuickResponseModel, cfg.TopicPlanModel, cfg.LessonModel, cfg.TestModel, cfg.GradingModel))
+
+	return cfg, p.Err()
 }
 
-func LoadConfig() *Config {
-	return &Config{
-		AccessSecret: readSecretFile("ACCESS_SECRET"),
-		OpenAIKey:    readSecretFile("OPENAI_API_KEY"),
-		DBHost:       readSecretFile("LESSON_DB_HOST"),
-		DBUser:       readSecretFile("LESSON_DB_USER"),
-		DBPassword:   readSecretFile("LESSON_DB_PASSWORD"),
-		DBName:       readSecretFile("LESSON_DB_NAME"),
-		DBPort:       readSecretFile("LESSON_DB_PORT"),
+// Print writes the resolved settings and where each came from, with secrets
+// redacted.
+func (c *Config) Print(w io.Writer) {
+	c.layers.Print(w)
+}
+
+// LoadConfig loads the configuration from args, usually os.Args[1:], and the
+// environment and exits on invalid settings. With --print-config it prints
+// the resolved configuration and exits.
+func LoadConfig(args []string) *Config {
+	cfg, err := Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if cfg != nil && cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\ninvalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
+	return cfg
 }
@@ -0,0 +1,98 @@
+package healthcheck
+
+import (
+	"context"
+	"lesson-service/pkg/llm"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"gorm.io/gorm"
+)
+
+const (
+	checkInterval = 10 * time.Second
+	checkTimeout  = 3 * time.Second
+)
+
+// Check reports why a dependency is unusable, or nil when it is fine.
+type Check func(ctx context.Context) error
+
+// Checker runs its checks on an interval and publishes the result through
+// the standard grpc.health.v1 service, both for the server as a whole ("")
+// and for each named service. Any failing check marks everything
+// NOT_SERVING, which takes the replica out of the gateway's load balancing.
+type Checker struct {
+	server   *health.Server
+	services []string
+	checks   map[string]Check
+	healthy  map[string]bool
+}
+
+func NewChecker(server *health.Server, services []string, checks map[string]Check) *Checker {
+	return &Checker{
+		server:   server,
+		services: services,
+		checks:   checks,
+		healthy:  make(map[string]bool),
+	}
+}
+
+// Start runs the checks once before returning, so the first status is
+// accurate, then keeps running them in the background.
+func (c *Checker) Start() {
+	c.run()
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			c.run()
+		}
+	}()
+}
+
+func (c *Checker) run() {
+	status := healthpb.HealthCheckResponse_SERVING
+	for name, check := range c.checks {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		err := check(ctx)
+		cancel()
+
+		wasHealthy, seen := c.healthy[name]
+		c.healthy[name] = err == nil
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			if !seen || wasHealthy {
+				slog.Warn("Health check failing", "check", name, "err", err)
+			}
+		} else if seen && !wasHealthy {
+			slog.Info("Health check recovered", "check", name)
+		}
+	}
+
+	c.server.SetServingStatus("", status)
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+// Database pings the database behind db.
+func Database(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// LLM fails while a model reference names a provider without the settings
+// it needs: a base URL for the local provider, and a key for OpenAI, whose
+// base URL defaults to the public API.
+func LLM(cfg llm.Config, refs ...string) Check {
+	return func(ctx context.Context) error {
+		return cfg.Validate(refs...)
+	}
+}
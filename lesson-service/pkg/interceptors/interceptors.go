@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	contextkeys "lesson-service/pkg/context-keys"
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/dgrijalva/jwt-go"
@@ -17,6 +17,9 @@ import (
 
 func NewStreamInterceptor(secretKey string) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isHealthCheck(info.FullMethod) {
+			return handler(srv, ss)
+		}
 		if metaD, ok := metadata.FromIncomingContext(ss.Context()); ok {
 			if tokens, found := metaD["authorization"]; found && len(tokens) > 0 {
 				if uid, ok := isValidToken(tokens[0], secretKey); ok {
@@ -28,13 +31,16 @@ func NewStreamInterceptor(secretKey string) grpc.StreamServerInterceptor {
 				}
 			}
 		}
-		log.Println("Invalid or missing auth token")
+		slog.WarnContext(ss.Context(), "Invalid or missing auth token", "method", info.FullMethod)
 		return status.Errorf(codes.Unauthenticated, "Stream req missing auth token")
 	}
 }
 
 func NewUnaryInterceptor(secretKey string) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if isHealthCheck(info.FullMethod) {
+			return handler(ctx, req)
+		}
 		if metaD, ok := metadata.FromIncomingContext(ctx); ok {
 			if tokens, found := metaD["authorization"]; found && len(tokens) > 0 {
 				if uid, ok := isValidToken(tokens[0], secretKey); ok {
@@ -44,11 +50,17 @@ func NewUnaryInterceptor(secretKey string) grpc.UnaryServerInterceptor {
 
 			}
 		}
-		log.Println("Invalid or missing auth token")
+		slog.WarnContext(ctx, "Invalid or missing auth token", "method", info.FullMethod)
 		return nil, status.Errorf(codes.Unauthenticated, "invalid or missing token")
 	}
 }
 
+// Health checks come from probes and load balancers that carry no user
+// token.
+func isHealthCheck(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
+
 func isValidToken(token string, secretKey string) (uint, bool) {
 	token = strings.TrimPrefix(token, "Bearer ")
 
@@ -59,17 +71,17 @@ func isValidToken(token string, secretKey string) (uint, bool) {
 		return []byte(secretKey), nil
 	})
 	if err != nil {
-		fmt.Printf("failed to parse token: %v", err)
+		slog.Warn("Failed to parse token", "err", err)
 		return 0, false
 	}
 	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
 		userID := claims["user_id"].(float64)
 		if !ok {
-			fmt.Println("User ID claim is not a number")
+			slog.Warn("User ID claim is not a number")
 			return 0, false
 		}
 		return uint(userID), true
 	}
-	fmt.Println("Invalid token or claims type incorrect")
+	slog.Warn("Invalid token or claims type incorrect")
 	return 0, false
 }
@@ -0,0 +1,180 @@
+// Package llm puts the language models the service calls behind a Provider
+// interface. Each feature is configured with a model reference such as
+// "openai/gpt-4o-mini" or "local/llama3.1:8b", where "openai" is the hosted
+// OpenAI API and "local" is any server speaking the OpenAI API, such as
+// Ollama or vLLM.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string
+	Content string
+}
+
+type Request struct {
+	Model       string
+	Messages    []Message
+	MaxTokens   int
+	Temperature float32
+}
+
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+type Response struct {
+	Content string
+	Usage   Usage
+}
+
+// Chunk is one piece of a streamed response. The last chunk of a stream may
+// carry only the usage.
+type Chunk struct {
+	Content string
+	Usage   *Usage
+}
+
+// Stream yields chunks until Recv returns io.EOF.
+type Stream interface {
+	Recv() (Chunk, error)
+	Close() error
+}
+
+// Function describes structured output: the model is asked to answer with
+// JSON arguments matching the Parameters JSON schema.
+type Function struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{}
+}
+
+type Provider interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+	Stream(ctx context.Context, req Request) (Stream, error)
+	// CompleteStructured returns the JSON arguments the model produced for fn.
+	CompleteStructured(ctx context.Context, req Request, fn Function) (string, Usage, error)
+}
+
+// Config holds the provider settings. Providers without settings are left
+// out; referring to one is a configuration error.
+type Config struct {
+	OpenAIKey     string
+	OpenAIBaseURL string
+
+	// LocalBaseURL is the /v1 endpoint of an OpenAI compatible server.
+	LocalBaseURL string
+	LocalKey     string
+}
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderLocal  = "local"
+)
+
+// Validate checks that every model reference is well formed and names a
+// configured provider.
+func (c Config) Validate(refs ...string) error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		provider, _, err := splitRef(ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch provider {
+		case ProviderOpenAI:
+			if strings.TrimSpace(c.OpenAIKey) == "" {
+				errs = append(errs, fmt.Errorf("%s needs OPENAI_API_KEY", ref))
+			}
+		case ProviderLocal:
+			if c.LocalBaseURL == "" {
+				errs = append(errs, fmt.Errorf("%s needs LLM_LOCAL_BASE_URL", ref))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Providers builds the providers that have settings, keyed by name.
+func (c Config) Providers() map[string]Provider {
+	providers := map[string]Provider{}
+	if key := strings.TrimSpace(c.OpenAIKey); key != "" {
+		providers[ProviderOpenAI] = NewOpenAI(key, c.OpenAIBaseURL)
+	}
+	if c.LocalBaseURL != "" {
+		providers[ProviderLocal] = NewCompatible(c.LocalBaseURL, c.LocalKey)
+	}
+	return providers
+}
+
+// Model is a provider bound to one of its models, as selected for a
+// feature. Requests made through it have their Model filled in.
+type Model struct {
+	provider Provider
+	name     string
+}
+
+// Resolve looks up the provider for ref among providers.
+func Resolve(providers map[string]Provider, ref string) (Model, error) {
+	name, model, err := splitRef(ref)
+	if err != nil {
+		return Model{}, err
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return Model{}, fmt.Errorf("llm: provider %q is not configured", name)
+	}
+	return Model{provider: provider, name: model}, nil
+}
+
+func NewModel(provider Provider, name string) Model {
+	return Model{provider: provider, name: name}
+}
+
+func (m Model) Name() string {
+	return m.name
+}
+
+func (m Model) Complete(ctx context.Context, req Request) (Response, error) {
+	req.Model = m.name
+	return m.provider.Complete(ctx, req)
+}
+
+func (m Model) Stream(ctx context.Context, req Request) (Stream, error) {
+	req.Model = m.name
+	return m.provider.Stream(ctx, req)
+}
+
+func (m Model) CompleteStructured(ctx context.Context, req Request, fn Function) (string, Usage, error) {
+	req.Model = m.name
+	return m.provider.CompleteStructured(ctx, req, fn)
+}
+
+func splitRef(ref string) (string, string, error) {
+	provider, model, ok := strings.Cut(ref, "/")
+	if !ok || model == "" {
+		return "", "", fmt.Errorf("llm: model %q should look like provider/model", ref)
+	}
+	if provider != ProviderOpenAI && provider != ProviderLocal {
+		return "", "", fmt.Errorf("llm: model %q has unknown provider %q, want %s or %s", ref, provider, ProviderOpenAI, ProviderLocal)
+	}
+	return provider, model, nil
+}
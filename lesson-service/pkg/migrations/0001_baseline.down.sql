@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS questions;
+DROP TABLE IF EXISTS tests;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS topic_plans;
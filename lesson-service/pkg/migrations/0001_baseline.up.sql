@@ -0,0 +1,61 @@
+-- Baseline matching the schema GORM AutoMigrate created for the lesson
+-- models. IF NOT EXISTS lets databases created by AutoMigrate adopt it
+-- unchanged.
+CREATE TABLE IF NOT EXISTS topic_plans (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title      text,
+    user_id    bigint,
+    objective  text,
+    standard   text,
+    completed  boolean
+);
+CREATE INDEX IF NOT EXISTS idx_topic_plans_deleted_at ON topic_plans (deleted_at);
+
+CREATE TABLE IF NOT EXISTS lessons (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    title         text,
+    topic_plan_id bigint,
+    objective     text,
+    information   text,
+    completed     boolean,
+    CONSTRAINT fk_topic_plans_lessons FOREIGN KEY (topic_plan_id) REFERENCES topic_plans (id)
+);
+CREATE INDEX IF NOT EXISTS idx_lessons_deleted_at ON lessons (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_lessons_topic_plan_id ON lessons (topic_plan_id);
+
+CREATE TABLE IF NOT EXISTS tests (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz,
+    title          text,
+    question_count bigint,
+    lesson_id      bigint,
+    passed         boolean,
+    CONSTRAINT fk_lessons_tests FOREIGN KEY (lesson_id) REFERENCES lessons (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tests_deleted_at ON tests (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_tests_lesson_id ON tests (lesson_id);
+
+CREATE TABLE IF NOT EXISTS questions (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    question_text text,
+    type          text,
+    options       text[],
+    answer        text,
+    answer_index  bigint,
+    matches       text[][],
+    test_id       bigint,
+    CONSTRAINT fk_tests_questions FOREIGN KEY (test_id) REFERENCES tests (id)
+);
+CREATE INDEX IF NOT EXISTS idx_questions_deleted_at ON questions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_questions_test_id ON questions (test_id);
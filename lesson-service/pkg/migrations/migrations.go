@@ -0,0 +1,8 @@
+// Package migrations holds the service's SQL migrations, embedded so the
+// binary can apply them. Add one with "lesson-service migrate create <name>".
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
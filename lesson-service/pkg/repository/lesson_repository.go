@@ -1,26 +1,38 @@
 package repository
 
 import (
+	"context"
 	"lesson-service/pkg/model"
 
 	"gorm.io/gorm"
 )
 
-type LessonRepository struct {
+// LessonRepository stores the lessons of topic plans.
+type LessonRepository interface {
+	CreateNewLesson(ctx context.Context, lesson *model.Lesson) error
+	FindLessonByID(ctx context.Context, lessonID uint) (*model.Lesson, error)
+	// GetAllLessonsByTopicPlanID lists a topic plan's lessons newest first
+	// with only their ID, title and completion loaded.
+	GetAllLessonsByTopicPlanID(ctx context.Context, topicPlanID uint) ([]*model.Lesson, error)
+	UpdateLesson(ctx context.Context, lesson *model.Lesson) (*model.Lesson, error)
+	UpdateLessonCompleted(ctx context.Context, lessonID uint, completed bool) error
+}
+
+type GormLessonRepository struct {
 	db *gorm.DB
 }
 
-func NewLessonRepository(db *gorm.DB) *LessonRepository {
-	return &LessonRepository{db: db}
+func NewLessonRepository(db *gorm.DB) *GormLessonRepository {
+	return &GormLessonRepository{db: db}
 }
 
-func (repo *LessonRepository) CreateNewLesson(lesson *model.Lesson) error {
-	return repo.db.Create(lesson).Error
+func (repo *GormLessonRepository) CreateNewLesson(ctx context.Context, lesson *model.Lesson) error {
+	return repo.db.WithContext(ctx).Create(lesson).Error
 }
 
-func (repo *LessonRepository) FindLessonByID(lessonID uint) (*model.Lesson, error) {
+func (repo *GormLessonRepository) FindLessonByID(ctx context.Context, lessonID uint) (*model.Lesson, error) {
 	var lesson model.Lesson
-	result := repo.db.First(&lesson, lessonID)
+	result := repo.db.WithContext(ctx).First(&lesson, lessonID)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -28,10 +40,10 @@ func (repo *LessonRepository) FindLessonByID(lessonID uint) (*model.Lesson, erro
 	return &lesson, nil
 }
 
-func (repo *LessonRepository) GetAllLessonsByTopicPlanID(topicPlanID uint) ([]*model.Lesson, error) {
+func (repo *GormLessonRepository) GetAllLessonsByTopicPlanID(ctx context.Context, topicPlanID uint) ([]*model.Lesson, error) {
 	var lessons []*model.Lesson
 
-	results := repo.db.Model(&model.Lesson{}).Select("id", "title").Where("topic_plan_id = ?", topicPlanID).Order("id DESC")
+	results := repo.db.WithContext(ctx).Model(&model.Lesson{}).Select("id", "title", "completed").Where("topic_plan_id = ?", topicPlanID).Order("id DESC")
 
 	if err := results.Find(&lessons).Error; err != nil {
 		return nil, err
@@ -39,10 +51,10 @@ func (repo *LessonRepository) GetAllLessonsByTopicPlanID(topicPlanID uint) ([]*m
 	return lessons, nil
 }
 
-func (repo *LessonRepository) UpdateLesson(lesson *model.Lesson) (*model.Lesson, error) {
-	return lesson, repo.db.Save(lesson).Error
+func (repo *GormLessonRepository) UpdateLesson(ctx context.Context, lesson *model.Lesson) (*model.Lesson, error) {
+	return lesson, repo.db.WithContext(ctx).Save(lesson).Error
 }
 
-func (repo *LessonRepository) UpdateLessonCompleted(lessonID uint, completed bool) error {
-	return repo.db.Model(&model.Lesson{}).Where("id = ?", lessonID).Update("completed", completed).Error
+func (repo *GormLessonRepository) UpdateLessonCompleted(ctx context.Context, lessonID uint, completed bool) error {
+	return repo.db.WithContext(ctx).Model(&model.Lesson{}).Where("id = ?", lessonID).Update("completed", completed).Error
 }
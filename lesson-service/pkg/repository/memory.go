@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"lesson-service/pkg/model"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	_ TopicPlanRepository = (*GormTopicPlanRepository)(nil)
+	_ LessonRepository    = (*GormLessonRepository)(nil)
+	_ TestRepository      = (*GormTestRepository)(nil)
+	_ TopicPlanRepository = (*memoryTopicPlanRepository)(nil)
+	_ LessonRepository    = (*memoryLessonRepository)(nil)
+	_ TestRepository      = (*memoryTestRepository)(nil)
+)
+
+// MemoryDB is an in-memory stand-in for the lesson database, for running
+// the services without Postgres. Its repositories behave like the GORM
+// ones: IDs and timestamps are assigned on create, nested lessons, tests
+// and questions are created with their parent, foreign keys are enforced,
+// associations are only loaded where the GORM query preloads them, and
+// lookups of missing rows fail the same way.
+type MemoryDB struct {
+	mu         sync.Mutex
+	topicPlans map[uint]model.TopicPlan
+	lessons    map[uint]model.Lesson
+	tests      map[uint]model.Test
+	questions  map[uint]model.Question
+	lastID     map[string]uint
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		topicPlans: make(map[uint]model.TopicPlan),
+		lessons:    make(map[uint]model.Lesson),
+		tests:      make(map[uint]model.Test),
+		questions:  make(map[uint]model.Question),
+		lastID:     make(map[string]uint),
+	}
+}
+
+func (db *MemoryDB) TopicPlanRepository() TopicPlanRepository {
+	return &memoryTopicPlanRepository{db: db}
+}
+
+func (db *MemoryDB) LessonRepository() LessonRepository {
+	return &memoryLessonRepository{db: db}
+}
+
+func (db *MemoryDB) TestRepository() TestRepository {
+	return &memoryTestRepository{db: db}
+}
+
+// The helpers below are called with mu held.
+
+func (db *MemoryDB) nextID(table string) uint {
+	db.lastID[table]++
+	return db.lastID[table]
+}
+
+// stamp assigns an ID to a new row and updates its timestamps.
+func (db *MemoryDB) stamp(table string, m *gorm.Model, id *uint) {
+	now := time.Now()
+	if *id == 0 {
+		*id = db.nextID(table)
+		m.CreatedAt = now
+	}
+	m.ID = *id
+	m.UpdatedAt = now
+}
+
+// saveTopicPlan upserts a topic plan and its lessons the way GORM's Create
+// and Save do: new children are inserted, existing ones only have their
+// foreign key updated.
+func (db *MemoryDB) saveTopicPlan(topicPlan *model.TopicPlan) error {
+	db.stamp("topic_plans", &topicPlan.Model, &topicPlan.ID)
+	stored := *topicPlan
+	stored.Lessons = nil
+	db.topicPlans[topicPlan.ID] = stored
+
+	for i := range topicPlan.Lessons {
+		lesson := &topicPlan.Lessons[i]
+		lesson.TopicPlanID = topicPlan.ID
+		if err := db.saveChildLesson(lesson); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) saveChildLesson(lesson *model.Lesson) error {
+	if existing, ok := db.lessons[lesson.ID]; ok {
+		existing.TopicPlanID = lesson.TopicPlanID
+		db.lessons[lesson.ID] = existing
+		return nil
+	}
+	return db.saveLesson(lesson)
+}
+
+func (db *MemoryDB) saveLesson(lesson *model.Lesson) error {
+	if _, ok := db.topicPlans[lesson.TopicPlanID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	db.stamp("lessons", &lesson.Model, &lesson.ID)
+	stored := *lesson
+	stored.Tests = nil
+	db.lessons[lesson.ID] = stored
+
+	for i := range lesson.Tests {
+		test := &lesson.Tests[i]
+		test.LessonID = lesson.ID
+		if existing, ok := db.tests[test.ID]; ok {
+			existing.LessonID = lesson.ID
+			db.tests[test.ID] = existing
+			continue
+		}
+		if err := db.saveTest(test); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) saveTest(test *model.Test) error {
+	if _, ok := db.lessons[test.LessonID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	db.stamp("tests", &test.Model, &test.ID)
+	stored := *test
+	stored.Questions = nil
+	db.tests[test.ID] = stored
+
+	for i := range test.Questions {
+		question := &test.Questions[i]
+		question.TestID = test.ID
+		if existing, ok := db.questions[question.ID]; ok {
+			existing.TestID = test.ID
+			db.questions[question.ID] = existing
+			continue
+		}
+		db.stamp("questions", &question.Model, &question.ID)
+		db.questions[question.ID] = copyQuestion(*question)
+	}
+	return nil
+}
+
+// copyQuestion keeps callers from mutating stored option and match slices.
+func copyQuestion(q model.Question) model.Question {
+	q.Options = append([]string(nil), q.Options...)
+	if q.Matches != nil {
+		matches := make([][]string, len(q.Matches))
+		for i, pair := range q.Matches {
+			matches[i] = append([]string(nil), pair...)
+		}
+		q.Matches = matches
+	}
+	return q
+}
+
+type memoryTopicPlanRepository struct {
+	db *MemoryDB
+}
+
+func (repo *memoryTopicPlanRepository) CreateNewTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	return repo.db.saveTopicPlan(topicPlan)
+}
+
+func (repo *memoryTopicPlanRepository) UpdateTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) (*model.TopicPlan, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	return topicPlan, repo.db.saveTopicPlan(topicPlan)
+}
+
+func (repo *memoryTopicPlanRepository) GetAllTopicPlansByUserID(ctx context.Context, id uint) ([]*model.TopicPlan, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var topicPlans []*model.TopicPlan
+	for _, topicPlan := range repo.db.topicPlans {
+		if topicPlan.UserID == id {
+			topicPlan := topicPlan
+			topicPlans = append(topicPlans, &topicPlan)
+		}
+	}
+	sort.Slice(topicPlans, func(i, j int) bool { return topicPlans[i].ID < topicPlans[j].ID })
+	return topicPlans, nil
+}
+
+func (repo *memoryTopicPlanRepository) GetTopicPlanByID(ctx context.Context, id uint) (*model.TopicPlan, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	topicPlan, ok := repo.db.topicPlans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &topicPlan, nil
+}
+
+func (repo *memoryTopicPlanRepository) UpdateTopicPlanCompleted(ctx context.Context, topicPlanID uint, completed bool) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if topicPlan, ok := repo.db.topicPlans[topicPlanID]; ok {
+		topicPlan.Completed = completed
+		topicPlan.UpdatedAt = time.Now()
+		repo.db.topicPlans[topicPlanID] = topicPlan
+	}
+	return nil
+}
+
+type memoryLessonRepository struct {
+	db *MemoryDB
+}
+
+func (repo *memoryLessonRepository) CreateNewLesson(ctx context.Context, lesson *model.Lesson) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	return repo.db.saveLesson(lesson)
+}
+
+func (repo *memoryLessonRepository) FindLessonByID(ctx context.Context, lessonID uint) (*model.Lesson, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	lesson, ok := repo.db.lessons[lessonID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &lesson, nil
+}
+
+func (repo *memoryLessonRepository) GetAllLessonsByTopicPlanID(ctx context.Context, topicPlanID uint) ([]*model.Lesson, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var lessons []*model.Lesson
+	for _, lesson := range repo.db.lessons {
+		if lesson.TopicPlanID == topicPlanID {
+			projected := &model.Lesson{ID: lesson.ID, Title: lesson.Title, Completed: lesson.Completed}
+			projected.Model.ID = lesson.ID
+			lessons = append(lessons, projected)
+		}
+	}
+	sort.Slice(lessons, func(i, j int) bool { return lessons[i].ID > lessons[j].ID })
+	return lessons, nil
+}
+
+func (repo *memoryLessonRepository) UpdateLesson(ctx context.Context, lesson *model.Lesson) (*model.Lesson, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	return lesson, repo.db.saveLesson(lesson)
+}
+
+func (repo *memoryLessonRepository) UpdateLessonCompleted(ctx context.Context, lessonID uint, completed bool) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if lesson, ok := repo.db.lessons[lessonID]; ok {
+		lesson.Completed = completed
+		lesson.UpdatedAt = time.Now()
+		repo.db.lessons[lessonID] = lesson
+	}
+	return nil
+}
+
+type memoryTestRepository struct {
+	db *MemoryDB
+}
+
+func (repo *memoryTestRepository) CreateNewTest(ctx context.Context, test *model.Test) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	return repo.db.saveTest(test)
+}
+
+// GetTestByTestID mirrors GORM's Find, which yields an empty test rather
+// than an error when there is no such row.
+func (repo *memoryTestRepository) GetTestByTestID(ctx context.Context, testID uint) (*model.Test, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	test := repo.db.tests[testID]
+	return &test, nil
+}
+
+func (repo *memoryTestRepository) GetAllTestsByLessonID(ctx context.Context, lessonID uint) ([]*model.Test, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	var tests []*model.Test
+	for _, test := range repo.db.tests {
+		if test.LessonID == lessonID {
+			test := test
+			tests = append(tests, &test)
+		}
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].ID < tests[j].ID })
+	return tests, nil
+}
+
+func (repo *memoryTestRepository) GetAllTestQuestionsByID(ctx context.Context, testID uint) (*model.Test, error) {
+	return repo.findWithQuestions(testID)
+}
+
+func (repo *memoryTestRepository) GetAllTestAnswersByID(ctx context.Context, testID uint) (*model.Test, error) {
+	return repo.findWithQuestions(testID)
+}
+
+func (repo *memoryTestRepository) findWithQuestions(testID uint) (*model.Test, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	stored, ok := repo.db.tests[testID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	test := &model.Test{ID: stored.ID, Title: stored.Title}
+	test.Model.ID = stored.ID
+	for _, question := range repo.db.questions {
+		if question.TestID == testID {
+			test.Questions = append(test.Questions, copyQuestion(question))
+		}
+	}
+	sort.Slice(test.Questions, func(i, j int) bool { return test.Questions[i].ID < test.Questions[j].ID })
+	return test, nil
+}
+
+func (repo *memoryTestRepository) UpdateTest(ctx context.Context, test *model.Test) (*model.Test, error) {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+	return test, repo.db.saveTest(test)
+}
+
+func (repo *memoryTestRepository) UpdateTestPassed(ctx context.Context, testID uint, passed bool) error {
+	repo.db.mu.Lock()
+	defer repo.db.mu.Unlock()
+
+	if test, ok := repo.db.tests[testID]; ok {
+		test.Passed = passed
+		test.UpdatedAt = time.Now()
+		repo.db.tests[testID] = test
+	}
+	return nil
+}
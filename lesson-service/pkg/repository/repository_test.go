@@ -0,0 +1,234 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lesson-service/pkg/migrate"
+	"lesson-service/pkg/migrations"
+	"lesson-service/pkg/model"
+	"lesson-service/pkg/repository"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The contract below runs against both implementations, so the in-memory
+// repositories used by the server tests keep behaving like the GORM ones.
+
+type repositories struct {
+	topicPlans repository.TopicPlanRepository
+	lessons    repository.LessonRepository
+	tests      repository.TestRepository
+}
+
+func TestMemoryRepositories(t *testing.T) {
+	testRepositories(t, func(t *testing.T) repositories {
+		db := repository.NewMemoryDB()
+		return repositories{topicPlans: db.TopicPlanRepository(), lessons: db.LessonRepository(), tests: db.TestRepository()}
+	})
+}
+
+func TestGormRepositories(t *testing.T) {
+	testRepositories(t, func(t *testing.T) repositories {
+		db := openTestDB(t)
+		return repositories{
+			topicPlans: repository.NewTopicPlanRepository(db),
+			lessons:    repository.NewLessonRepository(db),
+			tests:      repository.NewTestRepository(db),
+		}
+	})
+}
+
+func testRepositories(t *testing.T, open func(t *testing.T) repositories) {
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, open(t)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, open(t)) })
+	t.Run("UserScoping", func(t *testing.T) { testUserScoping(t, open(t)) })
+}
+
+func testOrdering(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	first := createTopicPlan(t, repos, 1, "Creation", "Day one", "Day two", "Day three")
+	second := createTopicPlan(t, repos, 1, "Exodus", "The plagues")
+
+	topicPlans, err := repos.topicPlans.GetAllTopicPlansByUserID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetAllTopicPlansByUserID: %v", err)
+	}
+	assertIDs(t, topicPlanIDs(topicPlans), first.ID, second.ID)
+
+	lessons, err := repos.lessons.GetAllLessonsByTopicPlanID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetAllLessonsByTopicPlanID: %v", err)
+	}
+	var ids []uint
+	for _, lesson := range lessons {
+		ids = append(ids, lesson.ID)
+		if lesson.Objective != "" {
+			t.Errorf("lesson %d listed with its objective", lesson.ID)
+		}
+	}
+	assertIDs(t, ids, first.Lessons[2].ID, first.Lessons[1].ID, first.Lessons[0].ID)
+
+	lessonID := first.Lessons[0].ID
+	older := createTest(t, repos, lessonID, "Quiz", "Who rested?", "What was made first?")
+	newer := createTest(t, repos, lessonID, "Retake", "Who rested?")
+	tests, err := repos.tests.GetAllTestsByLessonID(ctx, lessonID)
+	if err != nil {
+		t.Fatalf("GetAllTestsByLessonID: %v", err)
+	}
+	ids = nil
+	for _, test := range tests {
+		ids = append(ids, test.ID)
+	}
+	assertIDs(t, ids, older.ID, newer.ID)
+
+	withQuestions, err := repos.tests.GetAllTestQuestionsByID(ctx, older.ID)
+	if err != nil {
+		t.Fatalf("GetAllTestQuestionsByID: %v", err)
+	}
+	if len(withQuestions.Questions) != 2 || withQuestions.Questions[0].QuestionText != "Who rested?" {
+		t.Errorf("questions = %+v, want both in creation order", withQuestions.Questions)
+	}
+}
+
+func testNotFound(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	topicPlan := createTopicPlan(t, repos, 1, "Creation", "Day one")
+	missing := topicPlan.ID + topicPlan.Lessons[0].ID + 100
+
+	if _, err := repos.topicPlans.GetTopicPlanByID(ctx, missing); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetTopicPlanByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repos.lessons.FindLessonByID(ctx, missing); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindLessonByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repos.tests.GetAllTestQuestionsByID(ctx, missing); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetAllTestQuestionsByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	// GetTestByTestID uses Find, which yields an empty test instead.
+	if test, err := repos.tests.GetTestByTestID(ctx, missing); err != nil || test.ID != 0 {
+		t.Errorf("GetTestByTestID = %+v, %v, want an empty test", test, err)
+	}
+}
+
+func testUserScoping(t *testing.T, repos repositories) {
+	ctx := context.Background()
+	mine := createTopicPlan(t, repos, 1, "Creation", "Day one")
+	theirs := createTopicPlan(t, repos, 2, "Exodus", "The plagues")
+
+	for userID, want := range map[uint]uint{1: mine.ID, 2: theirs.ID} {
+		topicPlans, err := repos.topicPlans.GetAllTopicPlansByUserID(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetAllTopicPlansByUserID: %v", err)
+		}
+		assertIDs(t, topicPlanIDs(topicPlans), want)
+	}
+	topicPlans, err := repos.topicPlans.GetAllTopicPlansByUserID(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetAllTopicPlansByUserID: %v", err)
+	}
+	assertIDs(t, topicPlanIDs(topicPlans))
+}
+
+func createTopicPlan(t *testing.T, repos repositories, userID uint, title string, lessonTitles ...string) *model.TopicPlan {
+	t.Helper()
+	topicPlan := &model.TopicPlan{UserID: userID, Title: title, Objective: "Study " + title}
+	for _, lessonTitle := range lessonTitles {
+		topicPlan.Lessons = append(topicPlan.Lessons, model.Lesson{Title: lessonTitle, Objective: "Learn " + lessonTitle})
+	}
+	if err := repos.topicPlans.CreateNewTopicPlan(context.Background(), topicPlan); err != nil {
+		t.Fatalf("CreateNewTopicPlan: %v", err)
+	}
+	return topicPlan
+}
+
+func createTest(t *testing.T, repos repositories, lessonID uint, title string, questions ...string) *model.Test {
+	t.Helper()
+	test := &model.Test{LessonID: lessonID, Title: title, QuestionCount: uint(len(questions))}
+	for _, question := range questions {
+		test.Questions = append(test.Questions, model.Question{QuestionText: question, Type: model.ShortAnswer, Answer: "God"})
+	}
+	if err := repos.tests.CreateNewTest(context.Background(), test); err != nil {
+		t.Fatalf("CreateNewTest: %v", err)
+	}
+	return test
+}
+
+func topicPlanIDs(topicPlans []*model.TopicPlan) []uint {
+	var ids []uint
+	for _, topicPlan := range topicPlans {
+		ids = append(ids, topicPlan.ID)
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, got []uint, want ...uint) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+	}
+}
+
+// openTestDB migrates a fresh schema in the Postgres database named by
+// TEST_DATABASE_DSN and drops it when the test ends. The test is skipped
+// when the variable is unset.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), gormConfig)
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrate.New(sqlDB, migrations.FS, "lesson-service-test")
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// withSearchPath points every connection opened with dsn at schema. It
+// takes both URL and key=value DSNs.
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
@@ -1,27 +1,43 @@
 package repository
 
 import (
+	"context"
 	"lesson-service/pkg/model"
 
 	"gorm.io/gorm"
 )
 
-type TestRepository struct {
+// TestRepository stores tests and their questions.
+type TestRepository interface {
+	CreateNewTest(ctx context.Context, test *model.Test) error
+	GetTestByTestID(ctx context.Context, testID uint) (*model.Test, error)
+	// GetAllTestsByLessonID lists a lesson's tests oldest first, without
+	// their questions.
+	GetAllTestsByLessonID(ctx context.Context, lessonID uint) ([]*model.Test, error)
+	// GetAllTestQuestionsByID and GetAllTestAnswersByID load a test's title
+	// and questions, answers included.
+	GetAllTestQuestionsByID(ctx context.Context, testID uint) (*model.Test, error)
+	GetAllTestAnswersByID(ctx context.Context, testID uint) (*model.Test, error)
+	UpdateTest(ctx context.Context, test *model.Test) (*model.Test, error)
+	UpdateTestPassed(ctx context.Context, testID uint, passed bool) error
+}
+
+type GormTestRepository struct {
 	db *gorm.DB
 }
 
-func NewTestRepository(db *gorm.DB) *TestRepository {
-	return &TestRepository{db: db}
+func NewTestRepository(db *gorm.DB) *GormTestRepository {
+	return &GormTestRepository{db: db}
 }
 
-func (repo *TestRepository) CreateNewTest(test *model.Test) error {
-	return repo.db.Create(test).Error
+func (repo *GormTestRepository) CreateNewTest(ctx context.Context, test *model.Test) error {
+	return repo.db.WithContext(ctx).Create(test).Error
 }
 
-func (repo *TestRepository) GetTestByTestID(testID uint) (*model.Test, error) {
+func (repo *GormTestRepository) GetTestByTestID(ctx context.Context, testID uint) (*model.Test, error) {
 	var test *model.Test
 
-	result := repo.db.Model(&model.Test{}).Where("id = ?", testID)
+	result := repo.db.WithContext(ctx).Model(&model.Test{}).Where("id = ?", testID)
 
 	if err := result.Find(&test).Error; err != nil {
 		return nil, err
@@ -30,10 +46,10 @@ func (repo *TestRepository) GetTestByTestID(testID uint) (*model.Test, error) {
 	return test, nil
 }
 
-func (repo *TestRepository) GetAllTestsByLessonID(lessonID uint) ([]*model.Test, error) {
+func (repo *GormTestRepository) GetAllTestsByLessonID(ctx context.Context, lessonID uint) ([]*model.Test, error) {
 	var tests []*model.Test
 
-	result := repo.db.Model(&model.Test{}).Where("lesson_id = ?", lessonID)
+	result := repo.db.WithContext(ctx).Model(&model.Test{}).Where("lesson_id = ?", lessonID).Order("id")
 
 	if err := result.Find(&tests).Error; err != nil {
 		return nil, err
@@ -42,34 +58,33 @@ func (repo *TestRepository) GetAllTestsByLessonID(lessonID uint) ([]*model.Test,
 	return tests, nil
 }
 
-func (repo *TestRepository) GetAllTestQuestionsByID(testID uint) (*model.Test, error) {
-	var testQuestions *model.Test
-
-	results := repo.db.Model(&model.Test{}).Select("title", "questions").Where("id = ?", testID)
-
-	if err := results.Find(&testQuestions).Error; err != nil {
-		return nil, err
-	}
-
-	return testQuestions, nil
+func (repo *GormTestRepository) GetAllTestQuestionsByID(ctx context.Context, testID uint) (*model.Test, error) {
+	return repo.findWithQuestions(ctx, testID)
 }
 
-func (repo *TestRepository) GetAllTestAnswersByID(testID uint) (*model.Test, error) {
-	var testAnswers *model.Test
+func (repo *GormTestRepository) GetAllTestAnswersByID(ctx context.Context, testID uint) (*model.Test, error) {
+	return repo.findWithQuestions(ctx, testID)
+}
 
-	results := repo.db.Model(&model.Test{}).Select("title", "answers").Where("id = ?", testID)
+// findWithQuestions loads a test's title and its questions, which carry the
+// answers.
+func (repo *GormTestRepository) findWithQuestions(ctx context.Context, testID uint) (*model.Test, error) {
+	var test model.Test
 
-	if err := results.Find(&testAnswers).Error; err != nil {
+	err := repo.db.WithContext(ctx).Select("id", "title").Preload("Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&test, testID).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return testAnswers, nil
+	return &test, nil
 }
 
-func (repo *TestRepository) UpdateTest(test *model.Test) (*model.Test, error) {
-	return test, repo.db.Save(test).Error
+func (repo *GormTestRepository) UpdateTest(ctx context.Context, test *model.Test) (*model.Test, error) {
+	return test, repo.db.WithContext(ctx).Save(test).Error
 }
 
-func (repo *TestRepository) UpdateTestPassed(testID uint, passed bool) error {
-	return repo.db.Model(&model.Test{}).Where("id = ?", testID).Update("passed", passed).Error
+func (repo *GormTestRepository) UpdateTestPassed(ctx context.Context, testID uint, passed bool) error {
+	return repo.db.WithContext(ctx).Model(&model.Test{}).Where("id = ?", testID).Update("passed", passed).Error
 }
@@ -1,31 +1,43 @@
 package repository
 
 import (
+	"context"
 	"lesson-service/pkg/model"
 
 	"gorm.io/gorm"
 )
 
-type TopicPlanRepository struct {
+// TopicPlanRepository stores topic plans. Creating one creates its lessons.
+type TopicPlanRepository interface {
+	CreateNewTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) error
+	UpdateTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) (*model.TopicPlan, error)
+	// GetAllTopicPlansByUserID lists a user's topic plans oldest first,
+	// without their lessons.
+	GetAllTopicPlansByUserID(ctx context.Context, id uint) ([]*model.TopicPlan, error)
+	GetTopicPlanByID(ctx context.Context, id uint) (*model.TopicPlan, error)
+	UpdateTopicPlanCompleted(ctx context.Context, topicPlanID uint, completed bool) error
+}
+
+type GormTopicPlanRepository struct {
 	db *gorm.DB
 }
 
-func NewTopicPlanRepository(db *gorm.DB) *TopicPlanRepository {
-	return &TopicPlanRepository{db: db}
+func NewTopicPlanRepository(db *gorm.DB) *GormTopicPlanRepository {
+	return &GormTopicPlanRepository{db: db}
 }
 
-func (repo *TopicPlanRepository) CreateNewTopicPlan(topicPlan *model.TopicPlan) error {
-	return repo.db.Create(topicPlan).Error
+func (repo *GormTopicPlanRepository) CreateNewTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) error {
+	return repo.db.WithContext(ctx).Create(topicPlan).Error
 }
 
-func (repo *TopicPlanRepository) UpdateTopicPlan(topicPlan *model.TopicPlan) (*model.TopicPlan, error) {
-	return topicPlan, repo.db.Save(topicPlan).Error
+func (repo *GormTopicPlanRepository) UpdateTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) (*model.TopicPlan, error) {
+	return topicPlan, repo.db.WithContext(ctx).Save(topicPlan).Error
 }
 
-func (repo *TopicPlanRepository) GetAllTopicPlansByUserID(id uint) ([]*model.TopicPlan, error) {
+func (repo *GormTopicPlanRepository) GetAllTopicPlansByUserID(ctx context.Context, id uint) ([]*model.TopicPlan, error) {
 	var topicPlans []*model.TopicPlan
 
-	results := repo.db.Model(&model.TopicPlan{}).Where("user_id = ?", id)
+	results := repo.db.WithContext(ctx).Model(&model.TopicPlan{}).Where("user_id = ?", id).Order("id")
 
 	err := results.Find(&topicPlans).Error
 	if err != nil {
@@ -35,15 +47,15 @@ func (repo *TopicPlanRepository) GetAllTopicPlansByUserID(id uint) ([]*model.Top
 	return topicPlans, nil
 }
 
-func (repo *TopicPlanRepository) GetTopicPlanByID(id uint) (*model.TopicPlan, error) {
+func (repo *GormTopicPlanRepository) GetTopicPlanByID(ctx context.Context, id uint) (*model.TopicPlan, error) {
 	topicPlan := &model.TopicPlan{}
-	err := repo.db.Model(&model.TopicPlan{}).Where("id = ?", id).First(topicPlan).Error
+	err := repo.db.WithContext(ctx).Model(&model.TopicPlan{}).Where("id = ?", id).First(topicPlan).Error
 	if err != nil {
 		return nil, err
 	}
 	return topicPlan, nil
 }
 
-func (repo *TopicPlanRepository) UpdateTopicPlanCompleted(topicPlanID uint, completed bool) error {
-	return repo.db.Model(&model.TopicPlan{}).Where("id = ?", topicPlanID).Update("completed", completed).Error
+func (repo *GormTopicPlanRepository) UpdateTopicPlanCompleted(ctx context.Context, topicPlanID uint, completed bool) error {
+	return repo.db.WithContext(ctx).Model(&model.TopicPlan{}).Where("id = ?", topicPlanID).Update("completed", completed).Error
 }
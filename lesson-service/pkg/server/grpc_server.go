@@ -2,10 +2,10 @@ package server
 
 import (
 	"context"
-	"fmt"
 	"lesson-service/pkg/model"
 	"lesson-service/pkg/proto"
 	"lesson-service/pkg/service"
+	"log/slog"
 	"strings"
 )
 
@@ -13,7 +13,7 @@ type LessonServer struct {
 	topicService  *service.TopicPlanService
 	lessonService *service.LessonService
 	testService   *service.TestService
-	openAIService *service.OpenAIService
+	llmService    *service.LLMService
 	proto.UnimplementedLessonServiceServer
 }
 
@@ -21,32 +21,31 @@ func NewLessonServer(
 	topicService *service.TopicPlanService,
 	lessonService *service.LessonService,
 	testService *service.TestService,
-	openAIService *service.OpenAIService,
+	llmService *service.LLMService,
 ) *LessonServer {
 	return &LessonServer{
 		topicService:  topicService,
 		lessonService: lessonService,
 		testService:   testService,
-		openAIService: openAIService,
+		llmService:    llmService,
 	}
 }
 
 func (s *LessonServer) GenerateQuickResponse(ctx context.Context, req *proto.GenerateQuickResponseRequest) (*proto.GenerateQuickResponseResponse, error) {
-	fmt.Println("GenerateQuickResponse called with prompt:", req.Prompt)
+	slog.DebugContext(ctx, "GenerateQuickResponse called", "prompt", req.Prompt)
 
-	response, err := s.openAIService.GenerateQuickResponse(req.Prompt)
-	fmt.Println("Response from GenerateQuickResponse:", response)
+	response, err := s.llmService.GenerateQuickResponse(ctx, req.Prompt)
 	if err != nil {
-		fmt.Println("Error from GenerateQuickResponse:", err)
+		slog.ErrorContext(ctx, "GenerateQuickResponse failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println("Returning response from GenerateQuickResponse")
+	slog.DebugContext(ctx, "Returning response from GenerateQuickResponse")
 	return &proto.GenerateQuickResponseResponse{Response: response}, nil
 }
 
 func (s *LessonServer) GenerateTopicPlan(ctx context.Context, req *proto.GenerateTopicPlanRequest) (*proto.GenerateTopicPlanResponse, error) {
-	topicPlan, err := s.openAIService.GenerateTopicPlan(req.Prompt, uint(req.UserId), int(req.NumberOfLessons))
+	topicPlan, err := s.llmService.GenerateTopicPlan(ctx, req.Prompt, uint(req.UserId), int(req.NumberOfLessons))
 	if err != nil {
 		return nil, err
 	}
@@ -77,14 +76,14 @@ func (s *LessonServer) GenerateTopicPlan(ctx context.Context, req *proto.Generat
 
 func (s *LessonServer) GenerateLessons(ctx context.Context, req *proto.GenerateLessonsRequest) (*proto.GenerateLessonsResponse, error) {
 	topicPlanID := uint(req.TopicPlanId)
-	lessons, err := s.lessonService.GetAllLessonsByTopicPlanID(topicPlanID)
+	lessons, err := s.lessonService.GetAllLessonsByTopicPlanID(ctx, topicPlanID)
 	if err != nil {
 		return nil, err
 	}
 
 	var detailedLessons []*proto.Lesson
 	for _, lesson := range lessons {
-		detailedLesson, err := s.openAIService.GenerateDetailedLesson(lesson)
+		detailedLesson, err := s.llmService.GenerateDetailedLesson(ctx, lesson)
 		if err != nil {
 			return nil, err
 		}
@@ -102,12 +101,12 @@ func (s *LessonServer) GenerateLessons(ctx context.Context, req *proto.GenerateL
 }
 
 func (s *LessonServer) GenerateTest(ctx context.Context, req *proto.GenerateTestRequest) (*proto.GenerateTestResponse, error) {
-	lesson, err := s.lessonService.GetLessonByID(uint(req.LessonId))
+	lesson, err := s.lessonService.GetLessonByID(ctx, uint(req.LessonId))
 	if err != nil {
 		return nil, err
 	}
 
-	test, err := s.openAIService.GenerateTest(lesson, int(req.NumMultipleChoice), int(req.NumFillInTheBlank), int(req.NumShortAnswer), int(req.NumMatchOptions))
+	test, err := s.llmService.GenerateTest(ctx, lesson, int(req.NumMultipleChoice), int(req.NumFillInTheBlank), int(req.NumShortAnswer), int(req.NumMatchOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +157,7 @@ func (s *LessonServer) GenerateTest(ctx context.Context, req *proto.GenerateTest
 }
 
 func (s *LessonServer) GetTopicPlanByID(ctx context.Context, req *proto.GetTopicPlanByIDRequest) (*proto.GetTopicPlanByIDResponse, error) {
-	topicPlan, err := s.topicService.GetTopicPlanByID(uint(req.TopicPlanId))
+	topicPlan, err := s.topicService.GetTopicPlanByID(ctx, uint(req.TopicPlanId))
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +187,7 @@ func (s *LessonServer) GetTopicPlanByID(ctx context.Context, req *proto.GetTopic
 }
 
 func (s *LessonServer) GetAllTopicPlansByUID(ctx context.Context, req *proto.GetAllTopicPlansByUIDRequest) (*proto.GetAllTopicPlansByUIDResponse, error) {
-	topicPlans, err := s.topicService.GetAllTopicPlansByUserID(uint(req.UserId))
+	topicPlans, err := s.topicService.GetAllTopicPlansByUserID(ctx, uint(req.UserId))
 	if err != nil {
 		return nil, err
 	}
@@ -221,12 +220,10 @@ func (s *LessonServer) GetAllTopicPlansByUID(ctx context.Context, req *proto.Get
 }
 
 func (s *LessonServer) GetAllLessonPlansByTopicID(ctx context.Context, req *proto.GetAllLessonPlansByTopicIDRequest) (*proto.GetAllLessonPlansByTopicIDResponse, error) {
-	lessons, err := s.lessonService.GetAllLessonsByTopicPlanID(uint(req.TopicPlanId))
+	lessons, err := s.lessonService.GetAllLessonsByTopicPlanID(ctx, uint(req.TopicPlanId))
 	if err != nil {
 		return nil, err
 	}
-
-	fmt.Println("test 1")
 	var protoLessons []*proto.Lesson
 	for _, lesson := range lessons {
 		protoLesson := &proto.Lesson{
@@ -244,7 +241,7 @@ func (s *LessonServer) GetAllLessonPlansByTopicID(ctx context.Context, req *prot
 }
 
 func (s *LessonServer) GetLessonByID(ctx context.Context, req *proto.GetLessonByIDRequest) (*proto.GetLessonByIDResponse, error) {
-	lesson, err := s.lessonService.GetLessonByID(uint(req.LessonId))
+	lesson, err := s.lessonService.GetLessonByID(ctx, uint(req.LessonId))
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +260,7 @@ func (s *LessonServer) GetLessonByID(ctx context.Context, req *proto.GetLessonBy
 }
 
 func (s *LessonServer) GetAllTestsByLessonID(ctx context.Context, req *proto.GetAllTestsByLessonIDRequest) (*proto.GetAllTestsByLessonIDResponse, error) {
-	tests, err := s.testService.GetAllTestsByLessonID(uint(req.LessonId))
+	tests, err := s.testService.GetAllTestsByLessonID(ctx, uint(req.LessonId))
 	if err != nil {
 		return nil, err
 	}
@@ -294,7 +291,7 @@ func (s *LessonServer) GetAllTestsByLessonID(ctx context.Context, req *proto.Get
 }
 
 func (s *LessonServer) GetAllQuestionsByTestID(ctx context.Context, req *proto.GetAllQuestionsByTestIDRequest) (*proto.GetAllQuestionsByTestIDResponse, error) {
-	test, err := s.testService.GetAllTestQuestionsByID(uint(req.TestId))
+	test, err := s.testService.GetAllTestQuestionsByID(ctx, uint(req.TestId))
 	if err != nil {
 		return nil, err
 	}
@@ -321,7 +318,7 @@ func (s *LessonServer) GradeTest(ctx context.Context, req *proto.GradeTestReques
 		Answers: req.Answers,
 	}
 
-	score, feedback, passed, err := s.testService.GradeTest(*userAnswer)
+	score, feedback, passed, err := s.testService.GradeTest(ctx, *userAnswer)
 	if err != nil {
 		return nil, err
 	}
@@ -332,16 +329,16 @@ func (s *LessonServer) GradeTest(ctx context.Context, req *proto.GradeTestReques
 	}
 
 	if passed {
-		err = s.lessonService.LessonCompleted(uint(req.LessonId), passed)
+		err = s.lessonService.LessonCompleted(ctx, uint(req.LessonId), passed)
 		if err != nil {
 			return nil, err
 		}
-		done, err := s.topicService.IsTopicPlanComplete(uint(req.TopicPlanId))
+		done, err := s.topicService.IsTopicPlanComplete(ctx, uint(req.TopicPlanId))
 		if err != nil {
 			return nil, err
 		}
 		if done {
-			err = s.topicService.UpdateTopicPlanCompleted(uint(req.TopicPlanId), true)
+			err = s.topicService.UpdateTopicPlanCompleted(ctx, uint(req.TopicPlanId), true)
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,148 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"lesson-service/pkg/interceptors"
+	"lesson-service/pkg/llm"
+	"lesson-service/pkg/proto"
+	"lesson-service/pkg/repository"
+	"lesson-service/pkg/server"
+	"lesson-service/pkg/service"
+	"net"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "test-secret"
+
+// fakeProvider answers every structured request with arguments.
+type fakeProvider struct {
+	arguments string
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, req llm.Request) (llm.Response, error) {
+	return llm.Response{}, errors.New("not supported")
+}
+
+func (p *fakeProvider) Stream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *fakeProvider) CompleteStructured(ctx context.Context, req llm.Request, fn llm.Function) (string, llm.Usage, error) {
+	return p.arguments, llm.Usage{}, nil
+}
+
+// newTestClient serves a LessonServer backed by the in-memory repositories
+// over bufconn, behind the same auth interceptors as in production.
+func newTestClient(t *testing.T, provider llm.Provider) proto.LessonServiceClient {
+	t.Helper()
+	db := repository.NewMemoryDB()
+	topicPlanRepo, lessonRepo, testRepo := db.TopicPlanRepository(), db.LessonRepository(), db.TestRepository()
+	fakeModel := llm.NewModel(provider, "fake")
+	models := service.Models{QuickResponse: fakeModel, TopicPlan: fakeModel, Lesson: fakeModel, Test: fakeModel, Grading: fakeModel}
+
+	llmService := service.NewLLMService(models, lessonRepo, topicPlanRepo, testRepo)
+	lessonService := service.NewLessonService(lessonRepo)
+	topicPlanService := service.NewTopicPlanService(topicPlanRepo, lessonService)
+	testService := service.NewTestService(testRepo, llmService)
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptors.NewUnaryInterceptor(testSecret)),
+		grpc.StreamInterceptor(interceptors.NewStreamInterceptor(testSecret)),
+	)
+	proto.RegisterLessonServiceServer(grpcServer, server.NewLessonServer(topicPlanService, lessonService, testService, llmService))
+
+	lis := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return proto.NewLessonServiceClient(conn)
+}
+
+// asUser returns a context carrying an access token for userID, as the
+// gateway sends it.
+func asUser(t *testing.T, userID uint) context.Context {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestGenerateTopicPlanStoresPlanAndLessons(t *testing.T) {
+	client := newTestClient(t, &fakeProvider{arguments: `{
+		"title": "The Sanctuary",
+		"objective": "Understand the sanctuary service",
+		"lessons": [
+			{"title": "The Tabernacle", "objective": "Describe the tabernacle"},
+			{"title": "The Day of Atonement", "objective": "Explain Leviticus 16"}
+		]
+	}`})
+	ctx := asUser(t, 1)
+
+	generated, err := client.GenerateTopicPlan(ctx, &proto.GenerateTopicPlanRequest{UserId: 1, Prompt: "the sanctuary", NumberOfLessons: 2})
+	if err != nil {
+		t.Fatalf("GenerateTopicPlan: %v", err)
+	}
+	topicPlan := generated.GetTopicPlan()
+	if topicPlan.GetTitle() != "The Sanctuary" || len(topicPlan.GetLesson()) != 2 {
+		t.Fatalf("topic plan = %v, want the generated plan with 2 lessons", topicPlan)
+	}
+
+	plans, err := client.GetAllTopicPlansByUID(ctx, &proto.GetAllTopicPlansByUIDRequest{UserId: 1})
+	if err != nil {
+		t.Fatalf("GetAllTopicPlansByUID: %v", err)
+	}
+	if len(plans.GetTopicPlans()) != 1 || plans.GetTopicPlans()[0].GetId() != topicPlan.GetId() {
+		t.Errorf("topic plans = %v, want the generated plan", plans.GetTopicPlans())
+	}
+	plans, err = client.GetAllTopicPlansByUID(ctx, &proto.GetAllTopicPlansByUIDRequest{UserId: 2})
+	if err != nil {
+		t.Fatalf("GetAllTopicPlansByUID: %v", err)
+	}
+	if len(plans.GetTopicPlans()) != 0 {
+		t.Errorf("another user's topic plans = %v, want none", plans.GetTopicPlans())
+	}
+
+	lessons, err := client.GetAllLessonPlansByTopicID(ctx, &proto.GetAllLessonPlansByTopicIDRequest{TopicPlanId: topicPlan.GetId()})
+	if err != nil {
+		t.Fatalf("GetAllLessonPlansByTopicID: %v", err)
+	}
+	if len(lessons.GetLessons()) != 2 || lessons.GetLessons()[0].GetTitle() != "The Day of Atonement" {
+		t.Errorf("lessons = %v, want both, newest first", lessons.GetLessons())
+	}
+
+	lesson, err := client.GetLessonByID(ctx, &proto.GetLessonByIDRequest{LessonId: topicPlan.GetLesson()[0].GetId()})
+	if err != nil {
+		t.Fatalf("GetLessonByID: %v", err)
+	}
+	if lesson.GetLesson().GetObjective() != "Describe the tabernacle" || lesson.GetLesson().GetTopicPlanId() != topicPlan.GetId() {
+		t.Errorf("lesson = %v, want the stored lesson", lesson.GetLesson())
+	}
+}
+
+func TestCallsWithoutTokenAreRejected(t *testing.T) {
+	client := newTestClient(t, &fakeProvider{})
+
+	_, err := client.GetAllTopicPlansByUID(context.Background(), &proto.GetAllTopicPlansByUIDRequest{UserId: 1})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("err = %v, want Unauthenticated", err)
+	}
+}
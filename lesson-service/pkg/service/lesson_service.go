@@ -1,50 +1,51 @@
 package service
 
 import (
+	"context"
 	"lesson-service/pkg/model"
 	"lesson-service/pkg/repository"
 )
 
 type LessonService struct {
-	lessonRepository *repository.LessonRepository
+	lessonRepository repository.LessonRepository
 }
 
-func NewLessonService(lessonRepo *repository.LessonRepository) *LessonService {
+func NewLessonService(lessonRepo repository.LessonRepository) *LessonService {
 	return &LessonService{
 		lessonRepository: lessonRepo,
 	}
 }
 
-func (l *LessonService) CreateLesson(lesson *model.Lesson) (*model.Lesson, error) {
-	return lesson, l.lessonRepository.CreateNewLesson(lesson)
+func (l *LessonService) CreateLesson(ctx context.Context, lesson *model.Lesson) (*model.Lesson, error) {
+	return lesson, l.lessonRepository.CreateNewLesson(ctx, lesson)
 }
 
-func (l *LessonService) GetLessonByID(lessonID uint) (*model.Lesson, error) {
-	lesson, err := l.lessonRepository.FindLessonByID(lessonID)
+func (l *LessonService) GetLessonByID(ctx context.Context, lessonID uint) (*model.Lesson, error) {
+	lesson, err := l.lessonRepository.FindLessonByID(ctx, lessonID)
 	if err != nil {
 		return nil, err
 	}
 	return lesson, nil
 }
 
-func (l *LessonService) GetAllLessonsByTopicPlanID(topicID uint) ([]*model.Lesson, error) {
-	lessons, err := l.lessonRepository.GetAllLessonsByTopicPlanID(topicID)
+func (l *LessonService) GetAllLessonsByTopicPlanID(ctx context.Context, topicID uint) ([]*model.Lesson, error) {
+	lessons, err := l.lessonRepository.GetAllLessonsByTopicPlanID(ctx, topicID)
 	if err != nil {
 		return nil, err
 	}
 	return lessons, nil
 }
 
-func (l *LessonService) UpdateLesson(lesson *model.Lesson) (*model.Lesson, error) {
-	return l.lessonRepository.UpdateLesson(lesson)
+func (l *LessonService) UpdateLesson(ctx context.Context, lesson *model.Lesson) (*model.Lesson, error) {
+	return l.lessonRepository.UpdateLesson(ctx, lesson)
 }
 
-func (l *LessonService) LessonCompleted(lessonID uint, completed bool) error {
-	return l.lessonRepository.UpdateLessonCompleted(lessonID, completed)
+func (l *LessonService) LessonCompleted(ctx context.Context, lessonID uint, completed bool) error {
+	return l.lessonRepository.UpdateLessonCompleted(ctx, lessonID, completed)
 }
 
-func (l *LessonService) AreAllLessonsCompleted(topicPlanID uint) (bool, error) {
-	lessons, err := l.GetAllLessonsByTopicPlanID(topicPlanID)
+func (l *LessonService) AreAllLessonsCompleted(ctx context.Context, topicPlanID uint) (bool, error) {
+	lessons, err := l.GetAllLessonsByTopicPlanID(ctx, topicPlanID)
 	if err != nil {
 		return false, err
 	}
@@ -4,73 +4,73 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"lesson-service/pkg/config"
+	"lesson-service/pkg/llm"
 	"lesson-service/pkg/model"
 	"lesson-service/pkg/repository"
+	"log/slog"
 	"strings"
-
-	openai "github.com/sashabaranov/go-openai"
 )
 
-type OpenAIService struct {
-	Config              *config.Config
-	client              *openai.Client
-	topicPlanRepository *repository.TopicPlanRepository
-	lessonRepository    *repository.LessonRepository
-	testRepository      *repository.TestRepository
+// Models are the models chosen for each generation feature, so cheap work
+// such as quizzes can run on a local model while the rest stays hosted.
+type Models struct {
+	QuickResponse llm.Model
+	TopicPlan     llm.Model
+	Lesson        llm.Model
+	Test          llm.Model
+	Grading       llm.Model
 }
 
-func NewOpenAIService(cfg *config.Config, lsnRepository *repository.LessonRepository, tpcRepository *repository.TopicPlanRepository, tstRepository *repository.TestRepository) *OpenAIService {
-	apiKey := strings.TrimSpace(cfg.OpenAIKey)
-	if apiKey == "" {
-		fmt.Println("Error: API key is empty")
-		return nil
-	}
+type LLMService struct {
+	models              Models
+	topicPlanRepository repository.TopicPlanRepository
+	lessonRepository    repository.LessonRepository
+	testRepository      repository.TestRepository
+}
 
-	client := openai.NewClient(apiKey)
-	return &OpenAIService{
-		client:              client,
+func NewLLMService(models Models, lsnRepository repository.LessonRepository, tpcRepository repository.TopicPlanRepository, tstRepository repository.TestRepository) *LLMService {
+	return &LLMService{
+		models:              models,
 		lessonRepository:    lsnRepository,
 		topicPlanRepository: tpcRepository,
 		testRepository:      tstRepository,
 	}
 }
 
-func (s *OpenAIService) GenerateQuickResponse(prompt string) (string, error) {
-	fmt.Println("OpenAIService: GenerateQuickResponse called with prompt:", prompt)
+func (s *LLMService) GenerateQuickResponse(ctx context.Context, prompt string) (string, error) {
+	slog.DebugContext(ctx, "GenerateQuickResponse called", "prompt", prompt)
 
 	// Update the prompt to fit the new format
 	prompt = fmt.Sprintf("Provide a brief answer to the following question, including a title with no formating or special characters and a 250 to 400 character overview: %s", prompt)
 
 	// Create the chat completion request with a token limit
-	req := openai.ChatCompletionRequest{
-		Model:     "gpt-4o-mini",
-		Messages:  []openai.ChatCompletionMessage{{Role: "user", Content: prompt}},
+	req := llm.Request{
+		Messages:  []llm.Message{{Role: llm.RoleUser, Content: prompt}},
 		MaxTokens: 150, // Set an appropriate token limit for a concise response
 	}
 
-	fmt.Println("OpenAIService: Sending request to OpenAI API:", req)
-	resp, err := s.client.CreateChatCompletion(context.TODO(), req)
+	slog.DebugContext(ctx, "Sending request to LLM", "model", s.models.QuickResponse.Name(), "prompt", prompt)
+	resp, err := s.models.QuickResponse.Complete(ctx, req)
 	if err != nil {
-		fmt.Println("OpenAIService: Error calling OpenAI API:", err)
+		slog.ErrorContext(ctx, "Error calling LLM", "err", err)
 		return "", err
 	}
 
-	fmt.Println("OpenAIService: Received response from OpenAI API:", resp)
+	slog.DebugContext(ctx, "Received response from LLM", "prompt_tokens", resp.Usage.PromptTokens, "completion_tokens", resp.Usage.CompletionTokens)
 
 	// Extract the response content
-	response := strings.TrimSpace(resp.Choices[0].Message.Content)
+	response := strings.TrimSpace(resp.Content)
 
 	return response, nil
 }
 
 // GenerateTopicPlan generates a topic plan with a specified number of lessons
-func (s *OpenAIService) GenerateTopicPlan(prompt string, userID uint, numberOfLessons int) (*model.TopicPlan, error) {
+func (s *LLMService) GenerateTopicPlan(ctx context.Context, prompt string, userID uint, numberOfLessons int) (*model.TopicPlan, error) {
 	prompt = fmt.Sprintf("Create a topic plan for the following subject with %d lessons: %s", numberOfLessons, prompt)
 
-	fmt.Printf("Create a topic plan for the following number of lessons: %v\n", numberOfLessons)
+	slog.DebugContext(ctx, "Creating topic plan", "lessons", numberOfLessons)
 
-	functionDefinition := openai.FunctionDefinition{
+	function := llm.Function{
 		Name: "generate_topic_plan",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -101,39 +101,23 @@ func (s *OpenAIService) GenerateTopicPlan(prompt string, userID uint, numberOfLe
 		},
 	}
 
-	requestBody := openai.ChatCompletionRequest{
-		Model:     "gpt-4o-mini",
-		Messages:  []openai.ChatCompletionMessage{{Role: "user", Content: prompt}},
+	requestBody := llm.Request{
+		Messages:  []llm.Message{{Role: llm.RoleUser, Content: prompt}},
 		MaxTokens: 1000,
-		Functions: []openai.FunctionDefinition{functionDefinition},
-		FunctionCall: openai.FunctionCall{
-			Name: "generate_topic_plan",
-		},
 	}
 
-	fmt.Println("OpenAIService: Sending request to OpenAI API:", requestBody)
+	slog.DebugContext(ctx, "Sending request to LLM", "model", s.models.TopicPlan.Name(), "prompt", prompt)
 
-	resp, err := s.client.CreateChatCompletion(context.TODO(), requestBody)
+	functionCallArgs, usage, err := s.models.TopicPlan.CompleteStructured(ctx, requestBody, function)
 	if err != nil {
-		fmt.Println("OpenAIService: Error calling OpenAI API:", err)
+		slog.ErrorContext(ctx, "Error calling LLM", "err", err)
 		return nil, err
 	}
 
-	fmt.Println("OpenAIService: Received response from OpenAI API:", resp)
-
-	// Check if there is a valid function call response
-	if len(resp.Choices) == 0 || resp.Choices[0].Message.FunctionCall.Name != "generate_topic_plan" {
-		return nil, fmt.Errorf("unexpected response format or function call name: %v", resp.Choices)
-	}
-
-	// Check if the function call arguments are present
-	functionCallArgs := resp.Choices[0].Message.FunctionCall.Arguments
-	if functionCallArgs == "" {
-		return nil, fmt.Errorf("function call arguments are nil: %v", resp.Choices[0].Message.FunctionCall)
-	}
+	slog.DebugContext(ctx, "Received response from LLM", "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
 
 	// Print the raw function call arguments for debugging
-	fmt.Println("Raw function call arguments:", functionCallArgs)
+	slog.DebugContext(ctx, "Received function call arguments", "completion", functionCallArgs)
 
 	var topicPlanData struct {
 		Title     string `json:"title"`
@@ -146,7 +130,7 @@ func (s *OpenAIService) GenerateTopicPlan(prompt string, userID uint, numberOfLe
 
 	err = json.Unmarshal([]byte(functionCallArgs), &topicPlanData) // Convert string to []byte
 	if err != nil {
-		fmt.Println("OpenAIService: Error unmarshaling function call arguments:", err)
+		slog.ErrorContext(ctx, "Error unmarshaling function call arguments", "err", err)
 		return nil, err
 	}
 
@@ -165,9 +149,9 @@ func (s *OpenAIService) GenerateTopicPlan(prompt string, userID uint, numberOfLe
 		topicPlan.Lessons = append(topicPlan.Lessons, lesson)
 	}
 
-	err = s.topicPlanRepository.CreateNewTopicPlan(topicPlan)
+	err = s.topicPlanRepository.CreateNewTopicPlan(ctx, topicPlan)
 	if err != nil {
-		fmt.Println("OpenAIService: Error creating new topic plan:", err)
+		slog.ErrorContext(ctx, "Error creating new topic plan", "err", err)
 		return nil, err
 	}
 
@@ -175,10 +159,10 @@ func (s *OpenAIService) GenerateTopicPlan(prompt string, userID uint, numberOfLe
 }
 
 // GenerateDetailedLesson generates a detailed lesson based on a basic lesson objective
-func (s *OpenAIService) GenerateDetailedLesson(lesson *model.Lesson) (*model.Lesson, error) {
+func (s *LLMService) GenerateDetailedLesson(ctx context.Context, lesson *model.Lesson) (*model.Lesson, error) {
 	prompt := fmt.Sprintf("Create a detailed lesson plan for this lesson: '%s' and this objective: %s", lesson.Title, lesson.Objective)
 
-	functionDefinition := openai.FunctionDefinition{
+	function := llm.Function{
 		Name: "generate_detailed_lesson",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -197,18 +181,13 @@ func (s *OpenAIService) GenerateDetailedLesson(lesson *model.Lesson) (*model.Les
 		},
 	}
 
-	requestBody := openai.ChatCompletionRequest{
-		Model:       "gpt-4",
-		Messages:    []openai.ChatCompletionMessage{{Role: "user", Content: prompt}},
+	requestBody := llm.Request{
+		Messages:    []llm.Message{{Role: llm.RoleUser, Content: prompt}},
 		MaxTokens:   1000,
 		Temperature: 0.7,
-		Functions:   []openai.FunctionDefinition{functionDefinition},
-		FunctionCall: openai.FunctionCall{
-			Name: "generate_detailed_lesson",
-		},
 	}
 
-	resp, err := s.client.CreateChatCompletion(context.TODO(), requestBody)
+	functionCallArgs, _, err := s.models.Lesson.CompleteStructured(ctx, requestBody, function)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +198,7 @@ func (s *OpenAIService) GenerateDetailedLesson(lesson *model.Lesson) (*model.Les
 		Content   string `json:"content"`
 	}
 
-	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &lessonData)
+	err = json.Unmarshal([]byte(functionCallArgs), &lessonData)
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +207,7 @@ func (s *OpenAIService) GenerateDetailedLesson(lesson *model.Lesson) (*model.Les
 	lesson.Objective = lessonData.Objective
 	lesson.Information = lessonData.Content
 
-	err = s.lessonRepository.CreateNewLesson(lesson)
+	err = s.lessonRepository.CreateNewLesson(ctx, lesson)
 	if err != nil {
 		return nil, err
 	}
@@ -238,13 +217,13 @@ func (s *OpenAIService) GenerateDetailedLesson(lesson *model.Lesson) (*model.Les
 
 // Dynamically generates test based on the number of questions specified for each type.
 // Eventually will need to handle short answer grading for tests.
-func (s *OpenAIService) GenerateTest(lesson *model.Lesson, numMultipleChoice, numFillInTheBlank, numShortAnswer, numMatchOptions int) (*model.Test, error) {
+func (s *LLMService) GenerateTest(ctx context.Context, lesson *model.Lesson, numMultipleChoice, numFillInTheBlank, numShortAnswer, numMatchOptions int) (*model.Test, error) {
 	prompt := fmt.Sprintf(
 		"Create a test with the following number of questions based on the lesson content: %d multiple-choice, %d fill-in-the-blank, %d short answer, and %d match options. Here is the lesson content: %s",
 		numMultipleChoice, numFillInTheBlank, numShortAnswer, numMatchOptions, lesson.Information,
 	)
 
-	functionDefinition := openai.FunctionDefinition{
+	function := llm.Function{
 		Name: "generate_test",
 		Parameters: map[string]interface{}{
 			"type": "object",
@@ -296,18 +275,13 @@ func (s *OpenAIService) GenerateTest(lesson *model.Lesson, numMultipleChoice, nu
 		},
 	}
 
-	requestBody := openai.ChatCompletionRequest{
-		Model:       "gpt-4",
-		Messages:    []openai.ChatCompletionMessage{{Role: "user", Content: prompt}},
+	requestBody := llm.Request{
+		Messages:    []llm.Message{{Role: llm.RoleUser, Content: prompt}},
 		MaxTokens:   1000,
 		Temperature: 0.7,
-		Functions:   []openai.FunctionDefinition{functionDefinition},
-		FunctionCall: openai.FunctionCall{
-			Name: "generate_test",
-		},
 	}
 
-	resp, err := s.client.CreateChatCompletion(context.TODO(), requestBody)
+	functionCallArgs, _, err := s.models.Test.CompleteStructured(ctx, requestBody, function)
 	if err != nil {
 		return nil, err
 	}
@@ -325,7 +299,7 @@ func (s *OpenAIService) GenerateTest(lesson *model.Lesson, numMultipleChoice, nu
 		} `json:"questions"`
 	}
 
-	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &testData)
+	err = json.Unmarshal([]byte(functionCallArgs), &testData)
 	if err != nil {
 		return nil, err
 	}
@@ -350,7 +324,7 @@ func (s *OpenAIService) GenerateTest(lesson *model.Lesson, numMultipleChoice, nu
 		LessonID:      lesson.ID,
 	}
 
-	err = s.testRepository.CreateNewTest(test)
+	err = s.testRepository.CreateNewTest(ctx, test)
 	if err != nil {
 		return nil, err
 	}
@@ -358,11 +332,11 @@ func (s *OpenAIService) GenerateTest(lesson *model.Lesson, numMultipleChoice, nu
 	return test, nil
 }
 
-func (s *OpenAIService) GradeShortAnswer(userAnswer, correctAnswer string) (bool, string, error) {
+func (s *LLMService) GradeShortAnswer(ctx context.Context, userAnswer, correctAnswer string) (bool, string, error) {
 	prompt := fmt.Sprintf("Is the following answer correct based on the provided context? Context: %s Answer: %s", correctAnswer, userAnswer)
 
-	resp, err := s.client.CreateCompletion(context.TODO(), openai.CompletionRequest{
-		Prompt:      prompt,
+	resp, err := s.models.Grading.Complete(ctx, llm.Request{
+		Messages:    []llm.Message{{Role: llm.RoleUser, Content: prompt}},
 		MaxTokens:   60,
 		Temperature: 0.7,
 	})
@@ -370,7 +344,7 @@ func (s *OpenAIService) GradeShortAnswer(userAnswer, correctAnswer string) (bool
 		return false, "", err
 	}
 
-	result := strings.TrimSpace(resp.Choices[0].Text)
+	result := strings.TrimSpace(resp.Content)
 	isCorrect := strings.Contains(strings.ToLower(result), "yes")
 	return isCorrect, result, nil
 }
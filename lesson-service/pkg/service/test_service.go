@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"lesson-service/pkg/model"
 	"lesson-service/pkg/repository"
@@ -8,23 +9,23 @@ import (
 )
 
 type TestService struct {
-	testRepo      *repository.TestRepository
-	openAIService *OpenAIService
+	testRepo   repository.TestRepository
+	llmService *LLMService
 }
 
-func NewTestService(testRepo *repository.TestRepository, openAIService *OpenAIService) *TestService {
+func NewTestService(testRepo repository.TestRepository, llmService *LLMService) *TestService {
 	return &TestService{
-		testRepo:      testRepo,
-		openAIService: openAIService,
+		testRepo:   testRepo,
+		llmService: llmService,
 	}
 }
 
-func (ts *TestService) CreateNewTest(test *model.Test) (*model.Test, error) {
-	return test, ts.testRepo.CreateNewTest(test)
+func (ts *TestService) CreateNewTest(ctx context.Context, test *model.Test) (*model.Test, error) {
+	return test, ts.testRepo.CreateNewTest(ctx, test)
 }
 
-func (ts *TestService) GetTestByID(testID uint) (*model.Test, error) {
-	test, err := ts.testRepo.GetTestByTestID(testID)
+func (ts *TestService) GetTestByID(ctx context.Context, testID uint) (*model.Test, error) {
+	test, err := ts.testRepo.GetTestByTestID(ctx, testID)
 	if err != nil {
 		return nil, err
 	}
@@ -32,8 +33,8 @@ func (ts *TestService) GetTestByID(testID uint) (*model.Test, error) {
 	return test, err
 }
 
-func (ts *TestService) GetAllTestsByLessonID(lessonID uint) ([]*model.Test, error) {
-	test, err := ts.testRepo.GetAllTestsByLessonID(lessonID)
+func (ts *TestService) GetAllTestsByLessonID(ctx context.Context, lessonID uint) ([]*model.Test, error) {
+	test, err := ts.testRepo.GetAllTestsByLessonID(ctx, lessonID)
 	if err != nil {
 		return nil, err
 	}
@@ -41,8 +42,8 @@ func (ts *TestService) GetAllTestsByLessonID(lessonID uint) ([]*model.Test, erro
 	return test, nil
 }
 
-func (ts *TestService) GetAllTestAnswersByID(testID uint) (*model.Test, error) {
-	testAnswers, err := ts.testRepo.GetAllTestAnswersByID(testID)
+func (ts *TestService) GetAllTestAnswersByID(ctx context.Context, testID uint) (*model.Test, error) {
+	testAnswers, err := ts.testRepo.GetAllTestAnswersByID(ctx, testID)
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +51,8 @@ func (ts *TestService) GetAllTestAnswersByID(testID uint) (*model.Test, error) {
 	return testAnswers, nil
 }
 
-func (ts *TestService) GetAllTestQuestionsByID(testID uint) (*model.Test, error) {
-	testQuestions, err := ts.testRepo.GetAllTestQuestionsByID(testID)
+func (ts *TestService) GetAllTestQuestionsByID(ctx context.Context, testID uint) (*model.Test, error) {
+	testQuestions, err := ts.testRepo.GetAllTestQuestionsByID(ctx, testID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +60,8 @@ func (ts *TestService) GetAllTestQuestionsByID(testID uint) (*model.Test, error)
 	return testQuestions, nil
 }
 
-func (ts *TestService) GradeTest(userAnswers model.UserAnswers) (int, map[int]string, bool, error) {
-	test, err := ts.GetAllTestAnswersByID(userAnswers.TestID)
+func (ts *TestService) GradeTest(ctx context.Context, userAnswers model.UserAnswers) (int, map[int]string, bool, error) {
+	test, err := ts.GetAllTestAnswersByID(ctx, userAnswers.TestID)
 	if err != nil {
 		return 0, nil, false, err
 	}
@@ -74,7 +75,7 @@ func (ts *TestService) GradeTest(userAnswers model.UserAnswers) (int, map[int]st
 
 	for i, userAnswer := range userAnswers.Answers {
 		question := test.Questions[i]
-		isCorrect, feedbackMsg, err := ts.gradeQuestion(question, userAnswer)
+		isCorrect, feedbackMsg, err := ts.gradeQuestion(ctx, question, userAnswer)
 		if err != nil {
 			return 0, nil, false, err
 		}
@@ -87,7 +88,7 @@ func (ts *TestService) GradeTest(userAnswers model.UserAnswers) (int, map[int]st
 	passingScore := float64(len(test.Questions)) * 0.75
 	passed := float64(score) >= passingScore
 
-	err = ts.RecordTestResult(userAnswers.TestID, passed)
+	err = ts.RecordTestResult(ctx, userAnswers.TestID, passed)
 	if err != nil {
 		return 0, nil, false, err
 	}
@@ -95,14 +96,14 @@ func (ts *TestService) GradeTest(userAnswers model.UserAnswers) (int, map[int]st
 	return score, feedback, passed, nil
 }
 
-func (ts *TestService) gradeQuestion(question model.Question, userAnswer string) (bool, string, error) {
+func (ts *TestService) gradeQuestion(ctx context.Context, question model.Question, userAnswer string) (bool, string, error) {
 	switch question.Type {
 	case model.MultipleChoice:
 		return gradeMultipleChoice(question, userAnswer)
 	case model.FillInTheBlank:
 		return gradeFillInTheBlank(question, userAnswer)
 	case model.ShortAnswer:
-		return ts.gradeShortAnswer(question, userAnswer)
+		return ts.gradeShortAnswer(ctx, question, userAnswer)
 	case model.MatchOptions:
 		return ts.gradeMatchOptions(question, userAnswer)
 	default:
@@ -124,8 +125,8 @@ func gradeFillInTheBlank(question model.Question, userAnswer string) (bool, stri
 	return false, "Incorrect. Correct answer: " + question.Answer, nil
 }
 
-func (ts *TestService) gradeShortAnswer(question model.Question, userAnswer string) (bool, string, error) {
-	isCorrect, result, err := ts.openAIService.GradeShortAnswer(userAnswer, question.Answer)
+func (ts *TestService) gradeShortAnswer(ctx context.Context, question model.Question, userAnswer string) (bool, string, error) {
+	isCorrect, result, err := ts.llmService.GradeShortAnswer(ctx, userAnswer, question.Answer)
 	if err != nil {
 		return false, "Error grading short answer", err
 	}
@@ -151,6 +152,6 @@ func (ts *TestService) FlattenMatches(matches [][]string) []string {
 	return flat
 }
 
-func (ts *TestService) RecordTestResult(testID uint, passed bool) error {
-	return ts.testRepo.UpdateTestPassed(testID, passed)
+func (ts *TestService) RecordTestResult(ctx context.Context, testID uint, passed bool) error {
+	return ts.testRepo.UpdateTestPassed(ctx, testID, passed)
 }
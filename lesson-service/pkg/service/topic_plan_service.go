@@ -1,29 +1,30 @@
 package service
 
 import (
-	"fmt"
+	"context"
 	"lesson-service/pkg/model"
 	"lesson-service/pkg/repository"
+	"log/slog"
 )
 
 type TopicPlanService struct {
-	topicPlanRepo *repository.TopicPlanRepository
+	topicPlanRepo repository.TopicPlanRepository
 	lessonService *LessonService
 }
 
-func NewTopicPlanService(topicPlanRepo *repository.TopicPlanRepository, lessonService *LessonService) *TopicPlanService {
+func NewTopicPlanService(topicPlanRepo repository.TopicPlanRepository, lessonService *LessonService) *TopicPlanService {
 	return &TopicPlanService{
 		topicPlanRepo: topicPlanRepo,
 		lessonService: lessonService,
 	}
 }
 
-func (tp *TopicPlanService) CreateTopicPlan(topicPlan *model.TopicPlan) (*model.TopicPlan, error) {
-	return topicPlan, tp.topicPlanRepo.CreateNewTopicPlan(topicPlan)
+func (tp *TopicPlanService) CreateTopicPlan(ctx context.Context, topicPlan *model.TopicPlan) (*model.TopicPlan, error) {
+	return topicPlan, tp.topicPlanRepo.CreateNewTopicPlan(ctx, topicPlan)
 }
 
-func (tp *TopicPlanService) GetAllTopicPlansByUserID(userID uint) ([]*model.TopicPlan, error) {
-	topicPlans, err := tp.topicPlanRepo.GetAllTopicPlansByUserID(userID)
+func (tp *TopicPlanService) GetAllTopicPlansByUserID(ctx context.Context, userID uint) ([]*model.TopicPlan, error) {
+	topicPlans, err := tp.topicPlanRepo.GetAllTopicPlansByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -31,24 +32,24 @@ func (tp *TopicPlanService) GetAllTopicPlansByUserID(userID uint) ([]*model.Topi
 	return topicPlans, nil
 }
 
-func (tp *TopicPlanService) GetTopicPlanByID(topicPlanID uint) (*model.TopicPlan, error) {
-	topicPlan, err := tp.topicPlanRepo.GetTopicPlanByID(topicPlanID)
+func (tp *TopicPlanService) GetTopicPlanByID(ctx context.Context, topicPlanID uint) (*model.TopicPlan, error) {
+	topicPlan, err := tp.topicPlanRepo.GetTopicPlanByID(ctx, topicPlanID)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("Topic plan returned: ", topicPlan.Lessons)
+	slog.DebugContext(ctx, "Topic plan returned", "topic_plan_id", topicPlanID, "lessons", len(topicPlan.Lessons))
 	return topicPlan, nil
 }
 
-func (tp *TopicPlanService) IsTopicPlanComplete(topicPlanID uint) (bool, error) {
-	allComplete, err := tp.lessonService.AreAllLessonsCompleted(topicPlanID)
+func (tp *TopicPlanService) IsTopicPlanComplete(ctx context.Context, topicPlanID uint) (bool, error) {
+	allComplete, err := tp.lessonService.AreAllLessonsCompleted(ctx, topicPlanID)
 	if err != nil {
 		return false, err
 	}
 	return allComplete, nil
 }
 
-func (tp *TopicPlanService) UpdateTopicPlanCompleted(topicPlanID uint, completed bool) error {
-	return tp.topicPlanRepo.UpdateTopicPlanCompleted(topicPlanID, completed)
+func (tp *TopicPlanService) UpdateTopicPlanCompleted(ctx context.Context, topicPlanID uint, completed bool) error {
+	return tp.topicPlanRepo.UpdateTopicPlanCompleted(ctx, topicPlanID, completed)
 }
@@ -1,66 +1,188 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"user-microservice/pkg/api"
 	"user-microservice/pkg/config"
+	"user-microservice/pkg/healthcheck"
 	"user-microservice/pkg/interceptors"
-	"user-microservice/pkg/model"
+	"user-microservice/pkg/logging"
+	"user-microservice/pkg/metrics"
+	"user-microservice/pkg/mtls"
 	"user-microservice/pkg/proto"
 	"user-microservice/pkg/repository"
 	"user-microservice/pkg/server"
 	"user-microservice/pkg/service"
+	"user-microservice/pkg/tracing"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	//Load Config
-	cfg := config.LoadConfig()
+	cfg := config.LoadConfig(os.Args[1:])
+	logging.Setup("user-subscription-service", cfg.Log)
+
+	//mTLS for both the gRPC and HTTP listeners
+	var tlsStore *mtls.Store
+	if cfg.TLS.Enabled() {
+		store, err := mtls.NewStore(cfg.TLS)
+		if err != nil {
+			log.Fatalf("Failed to load mTLS material: %v", err)
+		}
+		tlsStore = store
+	} else {
+		slog.Info("mTLS disabled, serving plaintext gRPC and HTTP")
+	}
 
 	//Setup db connection
+	shutdownTracing, err := tracing.Setup(context.Background(), "user-subscription-service", cfg.TracingExporter)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	db, err := repository.NewDB(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to db: %v", err)
 	}
 
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to register db metrics: %v", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to register db tracing: %v", err)
+	}
+	metrics.Serve(cfg.MetricsAddr)
+
 	//init repos, services and handlers
 	userRepo := repository.NewUserRepository(db)
 	userService := service.NewUserService(userRepo)
 	authService := service.NewAuthService(cfg)
 	handler := api.NewHandler(userService, authService)
 
-	//setup gin router
+	//setup gin router, traced so spans continue from the gateway's HTTP calls
 	router := api.SetupRouter(handler)
+	httpHandler := otelhttp.NewHandler(router, "user-http")
 
-	lis, err := net.Listen("tcp", ":8081")
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(
-		interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
-		grpc.StreamInterceptor(interceptors.NewStreamInterceptor(cfg.AccessSecret)),
-	)
+	serverOpts := []grpc.ServerOption{
+		grpc.WaitForHandlers(true),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logging.UnaryServerInterceptor(), interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), logging.StreamServerInterceptor(), interceptors.NewStreamInterceptor(cfg.AccessSecret)),
+	}
+	if tlsStore != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsStore.ServerConfig())))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 	userServer := server.NewUserServer(userService, authService)
 	proto.RegisterUserServiceServer(grpcServer, userServer)
 
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthcheck.NewChecker(healthServer, []string{proto.UserService_ServiceDesc.ServiceName}, map[string]healthcheck.Check{
+		"database": healthcheck.Database(db),
+	}).Start()
+
 	//migrate db
-	if err := db.AutoMigrate(&model.User{}); err != nil {
-		log.Fatalf("failed to auto-migrate: %v", err)
+	if cfg.MigrateOnStart {
+		migrator, err := newMigrator(db)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: cfg.HTTPAddr, Handler: httpHandler}
 	go func() {
-		log.Println("HTTP server is listening on :8080")
-		if err := router.Run(); err != nil {
+		slog.Info("HTTP server is listening", "addr", cfg.HTTPAddr)
+		var err error
+		if tlsStore != nil {
+			httpServer.TLSConfig = tlsStore.ServerConfig()
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("Failed to run HTTP server: %v", err)
 		}
 	}()
 
-	log.Println("gRPC server is listening on ", lis.Addr())
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	go func() {
+		slog.Info("gRPC server is listening", "addr", lis.Addr().String())
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("Failed to serve: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down", "grace_period", cfg.ShutdownGracePeriod)
+
+	// NOT_SERVING steers the gateway's health-checked balancer to other
+	// replicas while the calls already running here finish.
+	healthServer.Shutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Grace period ended with HTTP requests still running", "err", err)
 	}
+	deadline, _ := shutdownCtx.Deadline()
+	stopGracefully(grpcServer, time.Until(deadline))
 
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close database", "err", err)
+		}
+	}
+	slog.Info("Server stopped")
+}
+
+// stopGracefully lets running calls finish within grace and then cancels the
+// rest. The server waits for cancelled handlers to return, so none of them
+// is left writing to a closed database.
+func stopGracefully(grpcServer *grpc.Server, grace time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(grace):
+		slog.Warn("Grace period ended, cancelling remaining calls", "grace_period", grace)
+		grpcServer.Stop()
+		<-stopped
+	}
 }
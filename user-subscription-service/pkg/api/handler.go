@@ -1,8 +1,7 @@
 package api
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"user-microservice/pkg/model"
 	"user-microservice/pkg/service"
@@ -39,9 +38,9 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		Email:    userCreds.Email,
 	}
 
-	createdUser, err := h.userService.CreateUser(&newUser, userCreds.Password)
+	createdUser, err := h.userService.CreateUser(c.Request.Context(), &newUser, userCreds.Password)
 	if err != nil {
-		log.Printf("error creating user: %v", err)
+		slog.ErrorContext(c.Request.Context(), "Error creating user", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
@@ -75,8 +74,8 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("Login attempt with username: %s, password: %s\n", loginCreds.Name, loginCreds.Password)
-	user, err := h.userService.AuthUser(loginCreds.Name, loginCreds.Password)
+	slog.DebugContext(c.Request.Context(), "Login attempt", "username", loginCreds.Name)
+	user, err := h.userService.AuthUser(c.Request.Context(), loginCreds.Name, loginCreds.Password)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication failed"})
 		return
@@ -110,11 +109,9 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("Received refresh token: %s\n", tokenRequest.RefreshToken)
-
 	userID, err := h.authService.VerifyToken(tokenRequest.RefreshToken, false)
 	if err != nil {
-		fmt.Printf("Invalid refresh token: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "Invalid refresh token", "err", err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
 	}
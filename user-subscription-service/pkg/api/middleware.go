@@ -0,0 +1,32 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+	"user-microservice/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLogger carries the gateway's request ID into the handler's context
+// and logs each request once it is served.
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logging.MetadataKey)
+		if id == "" {
+			id = logging.NewRequestID()
+		}
+		ctx := logging.WithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(logging.MetadataKey, id)
+
+		start := time.Now()
+		c.Next()
+
+		slog.InfoContext(ctx, "HTTP request served",
+			"method", c.Request.Method,
+			"path", c.FullPath(),
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds())
+	}
+}
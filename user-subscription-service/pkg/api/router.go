@@ -3,7 +3,8 @@ package api
 import "github.com/gin-gonic/gin"
 
 func SetupRouter(handler *Handler) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery(), requestLogger())
 
 	//routes
 	router.POST("/create_user", handler.CreateUser)
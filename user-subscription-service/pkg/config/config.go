@@ -1,8 +1,15 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"time"
+	"user-microservice/pkg/logging"
+	"user-microservice/pkg/mtls"
 )
 
 type Config struct {
@@ -13,25 +20,125 @@ type Config struct {
 	UserDBPassword string
 	UserDBName     string
 	UserDBPort     string
+
+	// GRPCAddr and HTTPAddr are where the gRPC and HTTP servers listen.
+	GRPCAddr string
+	HTTPAddr string
+
+	// TLS enables mTLS on the service's listeners when configured.
+	TLS mtls.Config
+
+	Log logging.Config
+
+	MetricsAddr string
+
+	// TracingExporter is "otlp", "stdout" or "none".
+	TracingExporter string
+
+	// MigrateOnStart applies pending migrations before serving. Replicas
+	// take turns through the migration lock.
+	MigrateOnStart bool
+
+	// ShutdownGracePeriod is how long running calls get to finish after
+	// SIGTERM before they are cancelled.
+	ShutdownGracePeriod time.Duration
+
+	// PrintConfig is set by --print-config.
+	PrintConfig bool
+
+	layers *layers
 }
 
-func readSecretFile(secretName string) string {
-	secretPath := "/run/secrets/" + secretName
-	content, err := os.ReadFile(secretPath)
+var settings = []setting{
+	{Key: "ACCESS_SECRET", Secret: true, Usage: "key that signs access tokens"},
+	{Key: "REFRESH_SECRET", Secret: true, Usage: "key that signs refresh tokens"},
+	{Key: "USER_DB_HOST", Default: "localhost", Usage: "database host"},
+	{Key: "USER_DB_PORT", Default: "5432", Usage: "database port"},
+	{Key: "USER_DB_USER", Usage: "database user"},
+	{Key: "USER_DB_PASSWORD", Secret: true, Usage: "database password"},
+	{Key: "USER_DB_NAME", Usage: "database name"},
+	{Key: "GRPC_ADDR", Default: ":8081", Usage: "gRPC listen address"},
+	{Key: "HTTP_ADDR", Default: ":8080", Usage: "HTTP listen address"},
+	{Key: "TLS_CA_FILE", Usage: "CA bundle that signs peer certificates"},
+	{Key: "TLS_CERT_FILE", Usage: "service certificate, enables mTLS"},
+	{Key: "TLS_KEY_FILE", Usage: "service private key"},
+	{Key: "TLS_ALLOWED_PEERS", Usage: "comma separated SANs accepted from peers"},
+	{Key: "LOG_LEVEL", Default: "info", Usage: "debug, info, warn or error"},
+	{Key: "LOG_FORMAT", Default: "json", Usage: "json or text"},
+	{Key: "LOG_REDACT_CONTENT", Default: "true", Usage: "redact user and model content in logs"},
+	{Key: "METRICS_ADDR", Default: ":9090", Usage: "Prometheus metrics listen address"},
+	{Key: "TRACING_EXPORTER", Default: "none", Usage: "otlp, stdout or none"},
+	{Key: "MIGRATE_ON_START", Default: "true", Usage: "apply pending database migrations on startup"},
+	{Key: "SHUTDOWN_GRACE_PERIOD", Default: "30s", Usage: "time running calls get to finish on shutdown"},
+}
+
+// Load resolves the configuration from args and the process environment.
+// The returned error lists every invalid setting; the Config is still
+// returned with it so --print-config can show what was resolved.
+func Load(args []string) (*Config, error) {
+	l, err := resolve("user-subscription-service", settings, args, os.LookupEnv)
 	if err != nil {
-		log.Fatalf("Failed to read secret file %s, error: %v", secretPath, err)
+		return nil, err
+	}
+
+	p := &parser{l: l}
+	cfg := &Config{
+		AccessSecret:   p.Required("ACCESS_SECRET"),
+		RefreshSecret:  p.Required("REFRESH_SECRET"),
+		UserDBHost:     p.Required("USER_DB_HOST"),
+		UserDBPort:     p.Required("USER_DB_PORT"),
+		UserDBUser:     p.Required("USER_DB_USER"),
+		UserDBPassword: p.Required("USER_DB_PASSWORD"),
+		UserDBName:     p.Required("USER_DB_NAME"),
+		GRPCAddr:       p.Addr("GRPC_ADDR"),
+		HTTPAddr:       p.Addr("HTTP_ADDR"),
+		TLS: mtls.Config{
+			CAFile:       p.String("TLS_CA_FILE"),
+			CertFile:     p.String("TLS_CERT_FILE"),
+			KeyFile:      p.String("TLS_KEY_FILE"),
+			AllowedPeers: p.List("TLS_ALLOWED_PEERS"),
+		},
+		Log: logging.Config{
+			Level:         p.OneOf("LOG_LEVEL", "debug", "info", "warn", "error"),
+			Format:        p.OneOf("LOG_FORMAT", "json", "text"),
+			RedactContent: p.Bool("LOG_REDACT_CONTENT"),
+		},
+		MetricsAddr:         p.Addr("METRICS_ADDR"),
+		TracingExporter:     p.OneOf("TRACING_EXPORTER", "otlp", "stdout", "none"),
+		MigrateOnStart:      p.Bool("MIGRATE_ON_START"),
+		ShutdownGracePeriod: p.Duration("SHUTDOWN_GRACE_PERIOD"),
+		PrintConfig:         l.printConfig,
+		layers:              l,
 	}
-	return string(content)
+	p.Check(cfg.TLS.Validate())
+
+	return cfg, p.Err()
 }
 
-func LoadConfig() *Config {
-	return &Config{
-		AccessSecret:   readSecretFile("ACCESS_SECRET"),
-		RefreshSecret:  readSecretFile("REFRESH_SECRET"),
-		UserDBHost:     readSecretFile("USER_DB_HOST"),
-		UserDBUser:     readSecretFile("USER_DB_USER"),
-		UserDBPassword: readSecretFile("USER_DB_PASSWORD"),
-		UserDBName:     readSecretFile("USER_DB_NAME"),
-		UserDBPort:     readSecretFile("USER_DB_PORT"),
+// Print writes the resolved settings and where each came from, with secrets
+// redacted.
+func (c *Config) Print(w io.Writer) {
+	c.layers.Print(w)
+}
+
+// LoadConfig loads the configuration from args, usually os.Args[1:], and the
+// environment and exits on invalid settings. With --print-config it prints
+// the resolved configuration and exits.
+func LoadConfig(args []string) *Config {
+	cfg, err := Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if cfg != nil && cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\ninvalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
+	return cfg
 }
@@ -0,0 +1,8 @@
+-- Baseline matching the schema GORM AutoMigrate created for the user model.
+-- IF NOT EXISTS lets databases created by AutoMigrate adopt it unchanged.
+CREATE TABLE IF NOT EXISTS users (
+    id       bigserial PRIMARY KEY,
+    username text UNIQUE,
+    email    text UNIQUE,
+    password text
+);
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"user-microservice/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	_ UserRepository = (*GormUserRepository)(nil)
+	_ UserRepository = (*MemoryUserRepository)(nil)
+)
+
+// MemoryUserRepository is an in-memory UserRepository for running the
+// service without Postgres. Like the users table it assigns IDs, rejects
+// duplicate usernames and emails with gorm.ErrDuplicatedKey and returns
+// gorm.ErrRecordNotFound for missing users.
+type MemoryUserRepository struct {
+	mu     sync.Mutex
+	users  map[uint]model.User
+	lastID uint
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: make(map[uint]model.User)}
+}
+
+func (repo *MemoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if user.ID == 0 {
+		repo.lastID++
+		user.ID = repo.lastID
+	} else if _, ok := repo.users[user.ID]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	return repo.save(user)
+}
+
+func (repo *MemoryUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (repo *MemoryUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	_, err := repo.find(func(user model.User) bool { return user.Email == email })
+	return err == nil, nil
+}
+
+func (repo *MemoryUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	_, err := repo.find(func(user model.User) bool { return user.Username == username })
+	return err == nil, nil
+}
+
+func (repo *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return repo.find(func(user model.User) bool { return user.Email == email })
+}
+
+func (repo *MemoryUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return repo.find(func(user model.User) bool { return user.Username == username })
+}
+
+func (repo *MemoryUserRepository) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if user.ID == 0 {
+		repo.lastID++
+		user.ID = repo.lastID
+	}
+	return user, repo.save(user)
+}
+
+func (repo *MemoryUserRepository) ListAllUsers(ctx context.Context) ([]*model.User, error) {
+	return repo.filter(func(model.User) bool { return true }), nil
+}
+
+func (repo *MemoryUserRepository) SearchUsersByUsername(ctx context.Context, pattern string) ([]*model.User, error) {
+	return repo.filter(func(user model.User) bool { return strings.Contains(user.Username, pattern) }), nil
+}
+
+func (repo *MemoryUserRepository) DeleteUserByID(ctx context.Context, userID uint) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.users[userID]; !ok {
+		return errors.New("no user found with given ID")
+	}
+	delete(repo.users, userID)
+	return nil
+}
+
+// save stores a copy of user, enforcing the unique columns. Callers hold mu.
+func (repo *MemoryUserRepository) save(user *model.User) error {
+	for id, existing := range repo.users {
+		if id != user.ID && (existing.Username == user.Username || existing.Email == user.Email) {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	repo.users[user.ID] = *user
+	return nil
+}
+
+func (repo *MemoryUserRepository) find(match func(user model.User) bool) (*model.User, error) {
+	users := repo.filter(match)
+	if len(users) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return users[0], nil
+}
+
+// filter returns copies of the matching users ordered by ID.
+func (repo *MemoryUserRepository) filter(match func(user model.User) bool) []*model.User {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var users []*model.User
+	for _, user := range repo.users {
+		if match(user) {
+			user := user
+			users = append(users, &user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
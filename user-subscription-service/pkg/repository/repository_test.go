@@ -0,0 +1,194 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"user-microservice/pkg/migrate"
+	"user-microservice/pkg/migrations"
+	"user-microservice/pkg/model"
+	"user-microservice/pkg/repository"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The contract below runs against both implementations, so the in-memory
+// repository used by the server tests keeps behaving like the GORM one.
+
+func TestMemoryUserRepository(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) repository.UserRepository {
+		return repository.NewMemoryUserRepository()
+	})
+}
+
+func TestGormUserRepository(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) repository.UserRepository {
+		return repository.NewUserRepository(openTestDB(t))
+	})
+}
+
+func testUserRepository(t *testing.T, open func(t *testing.T) repository.UserRepository) {
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, open(t)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, open(t)) })
+	t.Run("Uniqueness", func(t *testing.T) { testUniqueness(t, open(t)) })
+	t.Run("DeleteIsScopedToOneUser", func(t *testing.T) { testDelete(t, open(t)) })
+}
+
+func testOrdering(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	ruth := createUser(t, repo, "ruth")
+	naomi := createUser(t, repo, "naomi")
+	boaz := createUser(t, repo, "boaz")
+
+	users, err := repo.ListAllUsers(ctx)
+	if err != nil {
+		t.Fatalf("ListAllUsers: %v", err)
+	}
+	assertUserIDs(t, users, ruth.ID, naomi.ID, boaz.ID)
+
+	users, err = repo.SearchUsersByUsername(ctx, "o")
+	if err != nil {
+		t.Fatalf("SearchUsersByUsername: %v", err)
+	}
+	assertUserIDs(t, users, naomi.ID, boaz.ID)
+}
+
+func testNotFound(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := createUser(t, repo, "ruth")
+
+	if _, err := repo.GetByID(ctx, user.ID+100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetByID: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetByEmail: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByUsername(ctx, "nobody"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetByUsername: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if exists, err := repo.UsernameExists(ctx, "nobody"); err != nil || exists {
+		t.Errorf("UsernameExists = %v, %v, want false", exists, err)
+	}
+	if err := repo.DeleteUserByID(ctx, user.ID+100); err == nil {
+		t.Error("DeleteUserByID of a missing user succeeded")
+	}
+
+	found, err := repo.GetByEmail(ctx, user.Email)
+	if err != nil || found.ID != user.ID {
+		t.Errorf("GetByEmail = %+v, %v, want user %d", found, err, user.ID)
+	}
+}
+
+func testUniqueness(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	createUser(t, repo, "ruth")
+
+	if err := repo.Create(ctx, &model.User{Username: "ruth", Email: "other@example.com"}); err == nil {
+		t.Error("Create with a taken username succeeded")
+	}
+	if err := repo.Create(ctx, &model.User{Username: "other", Email: "ruth@example.com"}); err == nil {
+		t.Error("Create with a taken email succeeded")
+	}
+}
+
+func testDelete(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	ruth := createUser(t, repo, "ruth")
+	naomi := createUser(t, repo, "naomi")
+
+	if err := repo.DeleteUserByID(ctx, ruth.ID); err != nil {
+		t.Fatalf("DeleteUserByID: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, ruth.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetByID of a deleted user: err = %v, want gorm.ErrRecordNotFound", err)
+	}
+	users, err := repo.ListAllUsers(ctx)
+	if err != nil {
+		t.Fatalf("ListAllUsers: %v", err)
+	}
+	assertUserIDs(t, users, naomi.ID)
+}
+
+func createUser(t *testing.T, repo repository.UserRepository, username string) *model.User {
+	t.Helper()
+	user := &model.User{Username: username, Email: username + "@example.com", Password: "hash"}
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return user
+}
+
+func assertUserIDs(t *testing.T, users []*model.User, want ...uint) {
+	t.Helper()
+	got := make([]uint, len(users))
+	for i, user := range users {
+		got[i] = user.ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("user IDs = %v, want %v", got, want)
+	}
+}
+
+// openTestDB migrates a fresh schema in the Postgres database named by
+// TEST_DATABASE_DSN and drops it when the test ends. The test is skipped
+// when the variable is unset.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), gormConfig)
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to connect to db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrate.New(sqlDB, migrations.FS, "user-subscription-service-test")
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// withSearchPath points every connection opened with dsn at schema. It
+// takes both URL and key=value DSNs.
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
@@ -1,36 +1,52 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"user-microservice/pkg/model"
 
 	"gorm.io/gorm"
 )
 
-type UserRepository struct {
+// UserRepository stores users. Usernames and emails are unique.
+type UserRepository interface {
+	Create(ctx context.Context, user *model.User) error
+	GetByID(ctx context.Context, id uint) (*model.User, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	Update(ctx context.Context, user *model.User) (*model.User, error)
+	// ListAllUsers and SearchUsersByUsername return users ordered by ID.
+	ListAllUsers(ctx context.Context) ([]*model.User, error)
+	SearchUsersByUsername(ctx context.Context, pattern string) ([]*model.User, error)
+	DeleteUserByID(ctx context.Context, userID uint) error
+}
+
+type GormUserRepository struct {
 	db *gorm.DB
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
 }
 
-func (repo *UserRepository) Create(user *model.User) error {
-	return repo.db.Create(user).Error
+func (repo *GormUserRepository) Create(ctx context.Context, user *model.User) error {
+	return repo.db.WithContext(ctx).Create(user).Error
 }
 
-func (repo *UserRepository) GetByID(id uint) (*model.User, error) {
+func (repo *GormUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	result := repo.db.First(&user, id)
+	result := repo.db.WithContext(ctx).First(&user, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
-func (repo *UserRepository) EmailExists(email string) (bool, error) {
+func (repo *GormUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	var count int64
-	result := repo.db.Model(&model.User{}).Where("email = ?", email).Count(&count)
+	result := repo.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count)
 	if result.Error != nil {
 		return false, result.Error
 	}
@@ -38,9 +54,9 @@ func (repo *UserRepository) EmailExists(email string) (bool, error) {
 	return count > 0, nil
 }
 
-func (repo *UserRepository) UsernameExists(username string) (bool, error) {
+func (repo *GormUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
 	var count int64
-	result := repo.db.Model(&model.User{}).Where("username = ?", username).Count(&count)
+	result := repo.db.WithContext(ctx).Model(&model.User{}).Where("username = ?", username).Count(&count)
 	if result.Error != nil {
 		return false, result.Error
 	}
@@ -48,41 +64,41 @@ func (repo *UserRepository) UsernameExists(username string) (bool, error) {
 	return count > 0, nil
 }
 
-func (repo *UserRepository) GetByEmail(email string) (*model.User, error) {
+func (repo *GormUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	result := repo.db.Where("email = ?", email).First(&user)
+	result := repo.db.WithContext(ctx).Where("email = ?", email).First(&user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
-func (repo *UserRepository) GetByUsername(username string) (*model.User, error) {
+func (repo *GormUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	result := repo.db.Where("username = ?", username).First(&user)
+	result := repo.db.WithContext(ctx).Where("username = ?", username).First(&user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
-func (repo *UserRepository) Update(user *model.User) (*model.User, error) {
-	return user, repo.db.Save(user).Error
+func (repo *GormUserRepository) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	return user, repo.db.WithContext(ctx).Save(user).Error
 }
 
-func (repo *UserRepository) ListAllUsers() ([]*model.User, error) {
+func (repo *GormUserRepository) ListAllUsers(ctx context.Context) ([]*model.User, error) {
 	var users []*model.User
-	result := repo.db.Find(&users)
+	result := repo.db.WithContext(ctx).Order("id").Find(&users)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return users, nil
 }
 
-func (repo *UserRepository) SearchUsersByUsername(pattern string) ([]*model.User, error) {
+func (repo *GormUserRepository) SearchUsersByUsername(ctx context.Context, pattern string) ([]*model.User, error) {
 	var users []*model.User
 
-	result := repo.db.Where("username LIKE ?", "%"+pattern+"%").Find(&users)
+	result := repo.db.WithContext(ctx).Where("username LIKE ?", "%"+pattern+"%").Order("id").Find(&users)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -91,8 +107,8 @@ func (repo *UserRepository) SearchUsersByUsername(pattern string) ([]*model.User
 	return users, nil
 }
 
-func (repo *UserRepository) DeleteUserByID(userID uint) error {
-	result := repo.db.Delete(&model.User{}, userID)
+func (repo *GormUserRepository) DeleteUserByID(ctx context.Context, userID uint) error {
+	result := repo.db.WithContext(ctx).Delete(&model.User{}, userID)
 	if result.RowsAffected == 0 {
 		return errors.New("no user found with given ID")
 	}
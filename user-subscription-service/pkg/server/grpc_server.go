@@ -2,12 +2,14 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"user-microservice/pkg/proto"
 	"user-microservice/pkg/service"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type UserServer struct {
@@ -27,9 +29,9 @@ func (s *UserServer) VerifyUserPassword(ctx context.Context, req *proto.VerifyUs
 	userID := uint(req.GetId())
 	password := req.GetPassword()
 
-	success, err := s.userService.VerifyUserPassword(userID, password)
+	success, err := s.userService.VerifyUserPassword(ctx, userID, password)
 	if err != nil {
-		log.Printf("Failed to check password: %v", err)
+		slog.ErrorContext(ctx, "Failed to check password", "err", err)
 	}
 
 	return &proto.VerifyUserPasswordResponse{
@@ -40,10 +42,14 @@ func (s *UserServer) VerifyUserPassword(ctx context.Context, req *proto.VerifyUs
 
 func (s *UserServer) GetUserInfo(ctx context.Context, req *proto.GetUserInfoRequest) (*proto.GetUserInfoResponse, error) {
 	userID := uint(req.GetId())
-	log.Println("getting user")
-	user, err := s.userService.GetUserByID(userID)
+	slog.InfoContext(ctx, "Getting user")
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
 	if err != nil {
-		log.Printf("Failed to find user with id: %v, %v", userID, err)
+		slog.ErrorContext(ctx, "Failed to find user", "user_id", userID, "err", err)
+		return nil, status.Error(codes.Internal, "failed to find user")
 	}
 
 	resp := &proto.GetUserInfoResponse{
@@ -61,9 +67,9 @@ func (s *UserServer) UpdateUserPassword(ctx context.Context, req *proto.UpdateUs
 	password := req.GetPassword()
 	oldPass := req.GetOldPass()
 
-	err := s.userService.UpdatePassword(userID, oldPass, password)
+	err := s.userService.UpdatePassword(ctx, userID, oldPass, password)
 	if err != nil {
-		log.Printf("Failed to update password: %v", err)
+		slog.ErrorContext(ctx, "Failed to update password", "err", err)
 		return &proto.UpdateUserPasswordResponse{
 			Success: false,
 		}, status.Error(codes.Internal, "failed to update password")
@@ -79,9 +85,10 @@ func (s *UserServer) UpdateUserInfo(ctx context.Context, req *proto.UpdateUserIn
 	username := req.GetUsername()
 	email := req.GetEmail()
 
-	user, err := s.userService.UpdateUser(userID, username, email)
+	user, err := s.userService.UpdateUser(ctx, userID, username, email)
 	if err != nil {
-		log.Printf("Failed to update user: %v", err)
+		slog.ErrorContext(ctx, "Failed to update user", "err", err)
+		return nil, status.Error(codes.Internal, "failed to update user")
 	}
 
 	resp := &proto.UpdateUserInfoResponse{
@@ -95,9 +102,9 @@ func (s *UserServer) UpdateUserInfo(ctx context.Context, req *proto.UpdateUserIn
 }
 
 func (s *UserServer) ListUsers(ctx context.Context, req *proto.ListUsersRequest) (*proto.ListUsersResponse, error) {
-	usersList, err := s.userService.ListAllUsers()
+	usersList, err := s.userService.ListAllUsers(ctx)
 	if err != nil {
-		log.Printf("Unable to print all users: %v", err)
+		slog.ErrorContext(ctx, "Unable to print all users", "err", err)
 	}
 
 	var users []*proto.User
@@ -119,9 +126,9 @@ func (s *UserServer) ListUsers(ctx context.Context, req *proto.ListUsersRequest)
 func (s *UserServer) DeleteUser(ctx context.Context, req *proto.DeleteUserRequest) (*proto.DeleteUserResponse, error) {
 	userID := uint(req.GetId())
 	password := req.GetPassword()
-	err := s.userService.DeleteUser(userID, password)
+	err := s.userService.DeleteUser(ctx, userID, password)
 	if err != nil {
-		log.Printf("Failed to delete user: %v", err)
+		slog.ErrorContext(ctx, "Failed to delete user", "err", err)
 		return &proto.DeleteUserResponse{
 			Success: false,
 		}, status.Error(codes.Internal, "failed to delete user")
@@ -133,9 +140,9 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *proto.DeleteUserReques
 
 func (s *UserServer) SearchUsersByUsername(ctx context.Context, req *proto.SearchForUserRequest) (*proto.SearchForUsersResponse, error) {
 	username := req.GetUsername()
-	usersList, err := s.userService.SearchUsersByUsername(username)
+	usersList, err := s.userService.SearchUsersByUsername(ctx, username)
 	if err != nil {
-		log.Printf("unable to return users: %v", err)
+		slog.ErrorContext(ctx, "Unable to return users", "err", err)
 	}
 	var users []*proto.User
 	for _, user := range usersList {
@@ -0,0 +1,146 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"user-microservice/pkg/config"
+	"user-microservice/pkg/interceptors"
+	"user-microservice/pkg/model"
+	"user-microservice/pkg/proto"
+	"user-microservice/pkg/repository"
+	"user-microservice/pkg/server"
+	"user-microservice/pkg/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testServer struct {
+	client      proto.UserServiceClient
+	userService *service.UserService
+	authService *service.AuthService
+}
+
+// newTestServer serves a UserServer backed by the in-memory repository
+// over bufconn, behind the same auth interceptors as in production.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	cfg := &config.Config{AccessSecret: "access-secret", RefreshSecret: "refresh-secret"}
+	userService := service.NewUserService(repository.NewMemoryUserRepository())
+	authService := service.NewAuthService(cfg)
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptors.NewUnaryInterceptor(cfg.AccessSecret)),
+		grpc.StreamInterceptor(interceptors.NewStreamInterceptor(cfg.AccessSecret)),
+	)
+	proto.RegisterUserServiceServer(grpcServer, server.NewUserServer(userService, authService))
+
+	lis := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testServer{client: proto.NewUserServiceClient(conn), userService: userService, authService: authService}
+}
+
+// signUp creates a user with the password "password".
+func (s *testServer) signUp(t *testing.T, username string) *model.User {
+	t.Helper()
+	user, err := s.userService.CreateUser(context.Background(), &model.User{Username: username, Email: username + "@example.com"}, "password")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user
+}
+
+// asUser returns a context carrying an access token for user, as the
+// gateway sends it.
+func (s *testServer) asUser(t *testing.T, user *model.User) context.Context {
+	t.Helper()
+	token, err := s.authService.GenerateToken(user.ID, true)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestUserInfoAndPasswords(t *testing.T) {
+	s := newTestServer(t)
+	ruth := s.signUp(t, "ruth")
+	naomi := s.signUp(t, "naomi")
+	ctx := s.asUser(t, ruth)
+
+	info, err := s.client.GetUserInfo(ctx, &proto.GetUserInfoRequest{Id: uint32(ruth.ID)})
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if info.GetUser().GetUsername() != "ruth" || info.GetUser().GetEmail() != "ruth@example.com" {
+		t.Errorf("user = %v, want ruth", info.GetUser())
+	}
+	_, err = s.client.GetUserInfo(ctx, &proto.GetUserInfoRequest{Id: uint32(naomi.ID + 100)})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetUserInfo of a missing user: err = %v, want NotFound", err)
+	}
+
+	for password, want := range map[string]bool{"password": true, "wrong": false} {
+		verified, err := s.client.VerifyUserPassword(ctx, &proto.VerifyUserPasswordRequest{Id: uint32(ruth.ID), Password: password})
+		if err != nil {
+			t.Fatalf("VerifyUserPassword: %v", err)
+		}
+		if verified.GetIsAuthorized() != want {
+			t.Errorf("VerifyUserPassword(%q) = %v, want %v", password, verified.GetIsAuthorized(), want)
+		}
+	}
+
+	users, err := s.client.ListUsers(ctx, &proto.ListUsersRequest{})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	var names []string
+	for _, user := range users.GetUsers() {
+		names = append(names, user.GetUsername())
+	}
+	if fmt.Sprint(names) != "[ruth naomi]" {
+		t.Errorf("users = %v, want [ruth naomi]", names)
+	}
+}
+
+func TestDeleteUserNeedsPassword(t *testing.T) {
+	s := newTestServer(t)
+	ruth := s.signUp(t, "ruth")
+	ctx := s.asUser(t, ruth)
+
+	if _, err := s.client.DeleteUser(ctx, &proto.DeleteUserRequest{Id: uint32(ruth.ID), Password: "wrong"}); err == nil {
+		t.Fatal("DeleteUser with a wrong password succeeded")
+	}
+	deleted, err := s.client.DeleteUser(ctx, &proto.DeleteUserRequest{Id: uint32(ruth.ID), Password: "password"})
+	if err != nil || !deleted.GetSuccess() {
+		t.Fatalf("DeleteUser = %v, %v, want success", deleted, err)
+	}
+	_, err = s.client.GetUserInfo(ctx, &proto.GetUserInfoRequest{Id: uint32(ruth.ID)})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetUserInfo of a deleted user: err = %v, want NotFound", err)
+	}
+}
+
+func TestCallsWithoutTokenAreRejected(t *testing.T) {
+	s := newTestServer(t)
+
+	_, err := s.client.ListUsers(context.Background(), &proto.ListUsersRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("err = %v, want Unauthenticated", err)
+	}
+}
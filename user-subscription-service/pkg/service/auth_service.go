@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 	"user-microservice/pkg/config"
@@ -58,19 +59,19 @@ func (s *AuthService) VerifyToken(tokenString string, isAccessToken bool) (uint,
 	})
 
 	if err != nil {
-		fmt.Printf("Failed to parse token: %v\n", err)
+		slog.Warn("Failed to parse token", "err", err)
 		return 0, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
 		userID, err := getUserIDFromClaims(claims)
 		if err != nil {
-			fmt.Printf("Error getting user ID from claims: %v\n", err)
+			slog.Error("Error getting user ID from claims", "err", err)
 			return 0, fmt.Errorf("error getting user ID from claims: %w", err)
 		}
 		return userID, nil
 	}
-	fmt.Println("Invalid token or claims type incorrect")
+	slog.Warn("Invalid token or claims type incorrect")
 	return 0, fmt.Errorf("invalid token or claims")
 }
 
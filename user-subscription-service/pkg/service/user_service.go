@@ -1,8 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"user-microservice/pkg/model"
@@ -10,16 +11,16 @@ import (
 )
 
 type UserService struct {
-	userRepository *repository.UserRepository
+	userRepository repository.UserRepository
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
+func NewUserService(repo repository.UserRepository) *UserService {
 	return &UserService{
 		userRepository: repo,
 	}
 }
 
-func (s *UserService) CreateUser(user *model.User, password string) (*model.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, user *model.User, password string) (*model.User, error) {
 	err := user.SetPassword(password)
 	if err != nil {
 		return nil, err
@@ -33,7 +34,7 @@ func (s *UserService) CreateUser(user *model.User, password string) (*model.User
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		exists, err := s.userRepository.EmailExists(user.Email)
+		exists, err := s.userRepository.EmailExists(ctx, user.Email)
 		if err != nil {
 			errorCh <- err
 			return
@@ -44,7 +45,7 @@ func (s *UserService) CreateUser(user *model.User, password string) (*model.User
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		exists, err := s.userRepository.UsernameExists(user.Username)
+		exists, err := s.userRepository.UsernameExists(ctx, user.Username)
 		if err != nil {
 			errorCh <- err
 			return
@@ -83,7 +84,7 @@ func (s *UserService) CreateUser(user *model.User, password string) (*model.User
 		return nil, errors.New("username in use")
 	}
 
-	return user, s.userRepository.Create(user)
+	return user, s.userRepository.Create(ctx, user)
 }
 
 func collectErrors(errors []error) string {
@@ -94,10 +95,10 @@ func collectErrors(errors []error) string {
 	return errMsg
 }
 
-func (s *UserService) UpdatePassword(userID uint, oldPassword, password string) error {
-	user, err := s.GetUserByID(userID)
+func (s *UserService) UpdatePassword(ctx context.Context, userID uint, oldPassword, password string) error {
+	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
-		fmt.Printf("Error getting user by id: %v", err)
+		slog.ErrorContext(ctx, "Error getting user by id", "err", err)
 		return err
 	}
 	ok := user.ComparePassword(oldPassword)
@@ -106,22 +107,22 @@ func (s *UserService) UpdatePassword(userID uint, oldPassword, password string)
 	}
 	err = user.SetPassword(password)
 	if err != nil {
-		fmt.Printf("Error setting new password: %v", err)
+		slog.ErrorContext(ctx, "Error setting new password", "err", err)
 		return err
 	}
 	return nil
 }
 
-func (s *UserService) GetUserByID(userID uint) (*model.User, error) {
-	user, err := s.userRepository.GetByID(userID)
+func (s *UserService) GetUserByID(ctx context.Context, userID uint) (*model.User, error) {
+	user, err := s.userRepository.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-func (s *UserService) AuthUser(username, password string) (*model.User, error) {
-	user, err := s.userRepository.GetByUsername(username)
+func (s *UserService) AuthUser(ctx context.Context, username, password string) (*model.User, error) {
+	user, err := s.userRepository.GetByUsername(ctx, username)
 	if err != nil {
 		return nil, err
 	}
@@ -132,13 +133,13 @@ func (s *UserService) AuthUser(username, password string) (*model.User, error) {
 	return user, nil
 }
 
-func (s *UserService) UpdateUser(userID uint, username, email string) (*model.User, error) {
+func (s *UserService) UpdateUser(ctx context.Context, userID uint, username, email string) (*model.User, error) {
 	updatedUser := model.User{
 		Username: username,
 		Email:    email,
 	}
 
-	user, err := s.userRepository.Update(&updatedUser)
+	user, err := s.userRepository.Update(ctx, &updatedUser)
 	if err != nil {
 		return nil, err
 	}
@@ -146,8 +147,8 @@ func (s *UserService) UpdateUser(userID uint, username, email string) (*model.Us
 	return user, nil
 }
 
-func (s *UserService) ListAllUsers() ([]*model.User, error) {
-	users, err := s.userRepository.ListAllUsers()
+func (s *UserService) ListAllUsers(ctx context.Context) ([]*model.User, error) {
+	users, err := s.userRepository.ListAllUsers(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +156,7 @@ func (s *UserService) ListAllUsers() ([]*model.User, error) {
 	return users, nil
 }
 
-func (s *UserService) SearchUsersByUsername(pattern string) ([]*model.User, error) {
+func (s *UserService) SearchUsersByUsername(ctx context.Context, pattern string) ([]*model.User, error) {
 	trimmedPattern := strings.TrimSpace(pattern)
 
 	if len(trimmedPattern) > 100 {
@@ -165,19 +166,19 @@ func (s *UserService) SearchUsersByUsername(pattern string) ([]*model.User, erro
 	sanitizedPattern := strings.ReplaceAll(trimmedPattern, "%", "\\%")
 	sanitizedPattern = strings.ReplaceAll(sanitizedPattern, "_", "\\_")
 
-	users, err := s.userRepository.SearchUsersByUsername(sanitizedPattern)
+	users, err := s.userRepository.SearchUsersByUsername(ctx, sanitizedPattern)
 	if err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-func (s *UserService) DeleteUser(userID uint, password string) error {
+func (s *UserService) DeleteUser(ctx context.Context, userID uint, password string) error {
 	if userID == 0 {
 		return errors.New("invalid user ID")
 	}
 
-	ok, err := s.VerifyUserPassword(userID, password)
+	ok, err := s.VerifyUserPassword(ctx, userID, password)
 	if err != nil {
 		return err
 	}
@@ -186,19 +187,19 @@ func (s *UserService) DeleteUser(userID uint, password string) error {
 		return errors.New("invalid password")
 	}
 
-	err = s.userRepository.DeleteUserByID(userID)
+	err = s.userRepository.DeleteUserByID(ctx, userID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *UserService) VerifyUserPassword(userID uint, password string) (bool, error) {
+func (s *UserService) VerifyUserPassword(ctx context.Context, userID uint, password string) (bool, error) {
 	if userID == 0 {
 		return false, errors.New("invalid user ID")
 	}
 
-	user, err := s.GetUserByID(userID)
+	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
 		return false, err
 	}